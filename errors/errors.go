@@ -0,0 +1,68 @@
+// Package errors holds the errors returned by every client of the library, they are
+// compared with errors.Is and the details read with errors.As on *ProviderError.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrNotConnected = errors.New("not connected")
+	ErrDisabled     = errors.New("disabled")
+	ErrConflict     = errors.New("conflict")
+)
+
+// ProviderError is returned by the clients when an operation fails, Status is the
+// status code of the server when it answered with one. It unwraps to its Cause.
+type ProviderError struct {
+	Provider string
+	Tag      string
+	Op       string
+	Status   int
+	Cause    error
+}
+
+func (e *ProviderError) Error() string {
+	msg := e.Provider
+	if len(e.Tag) != 0 {
+		msg += fmt.Sprintf(" %q", e.Tag)
+	}
+	if len(e.Op) != 0 {
+		msg += " " + e.Op
+	}
+	if e.Status != 0 {
+		msg += fmt.Sprintf(" [%d]", e.Status)
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Cause)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Cause
+}
+
+func New(provider, tag, op string, cause error) error {
+	return &ProviderError{Provider: provider, Tag: tag, Op: op, Cause: cause}
+}
+
+// WithStatus returns the error of an operation the server answered with the status
+// code, 404 wraps ErrNotFound and 409 ErrConflict.
+func WithStatus(provider, tag, op string, status int, cause error) error {
+	switch status {
+	case http.StatusNotFound:
+		cause = fmt.Errorf("%w: %v", ErrNotFound, cause)
+	case http.StatusConflict:
+		cause = fmt.Errorf("%w: %v", ErrConflict, cause)
+	}
+	return &ProviderError{Provider: provider, Tag: tag, Op: op, Status: status, Cause: cause}
+}
+
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/fajarardiyanto/flt-go-database/lib"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+func main() {
+	logger := log.NewLib()
+	logger.Init("Modules Config")
+
+	db := lib.NewLib()
+	db.Init(logger)
+
+	// every value can be overridden from the environment, e.g. DATABASE_SQL_PRIMARY_PASSWORD
+	if err := db.LoadFromFile("example/config/config.yaml"); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	if rdb, ok := db.GetRedis("cache"); ok {
+		if err := rdb.Set(context.Background(), "test", "test", 10*time.Minute); err != nil {
+			logger.Error(err)
+			return
+		}
+	}
+
+	if mysql, ok := db.GetSQL("primary"); ok {
+		var version string
+		if err := mysql.Orm().Raw(`SELECT @@VERSION`).Scan(&version).Error; err != nil {
+			logger.Error(err)
+			return
+		}
+		logger.Info(version)
+	}
+}
@@ -0,0 +1,88 @@
+package interfaces
+
+import (
+	"sync"
+)
+
+// Acknowledger settles a delivery of a consumer with RabbitMQOptions.ManualAck.
+type Acknowledger interface {
+	Ack() error
+	Nack(requeue bool) error
+	Reject() error
+}
+
+// ConsumerHandler is a consumer callback reporting the outcome of the message, see
+// AckAfter.
+type ConsumerHandler func(Messages, ConsumerCallbackIsDone) error
+
+// Ack acknowledges the delivery, a no-op when the consumer acks on receipt.
+func (d ConsumerCallbackIsDone) Ack() error {
+	if d.Acknowledger == nil {
+		return nil
+	}
+	return d.Acknowledger.Ack()
+}
+
+// Nack gives the delivery back to the broker, which redelivers it when requeue is set.
+func (d ConsumerCallbackIsDone) Nack(requeue bool) error {
+	if d.Acknowledger == nil {
+		return nil
+	}
+	return d.Acknowledger.Nack(requeue)
+}
+
+// Reject drops the delivery, or dead-letters it when the queue has a dead letter
+// exchange.
+func (d ConsumerCallbackIsDone) Reject() error {
+	if d.Acknowledger == nil {
+		return nil
+	}
+	return d.Acknowledger.Reject()
+}
+
+// Delivery is an Acknowledger settling once, the calls after the first one are
+// no-ops.
+type Delivery struct {
+	ack    func() error
+	nack   func(requeue bool) error
+	reject func() error
+	once   sync.Once
+}
+
+func NewDelivery(ack func() error, nack func(requeue bool) error, reject func() error) *Delivery {
+	return &Delivery{ack: ack, nack: nack, reject: reject}
+}
+
+func (d *Delivery) settle(fn func() error) (err error) {
+	d.once.Do(func() {
+		err = fn()
+	})
+	return
+}
+
+func (d *Delivery) Ack() error {
+	return d.settle(d.ack)
+}
+
+func (d *Delivery) Nack(requeue bool) error {
+	return d.settle(func() error {
+		return d.nack(requeue)
+	})
+}
+
+func (d *Delivery) Reject() error {
+	return d.settle(d.reject)
+}
+
+// AckAfter returns the callback running handler, the delivery is acked when it
+// returns nil, or nacked and requeued when requeue is set when it returns an error.
+// A delivery settled by the handler itself is left as it is.
+func AckAfter(handler ConsumerHandler, requeue bool) ConsumerCallback {
+	return func(msg Messages, done ConsumerCallbackIsDone) {
+		if err := handler(msg, done); err != nil {
+			_ = done.Nack(requeue)
+			return
+		}
+		_ = done.Ack()
+	}
+}
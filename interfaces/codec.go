@@ -0,0 +1,205 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	databaseproto "github.com/fajarardiyanto/module-proto/go/modules/database"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// HeaderContentType is the message header holding the content type of the codec
+// which encoded the body.
+const HeaderContentType = "content-type"
+
+// Codec writes and reads the body of the broker messages for an Encoding. The id
+// is the one of the message, only used by the codecs wrapping the body in an
+// envelope.
+type Codec interface {
+	ContentType() string
+	Encode(id string, data interface{}) ([]byte, error)
+	Decode(raw []byte, data interface{}) error
+}
+
+var (
+	codecs = map[Encoding]Codec{
+		EncodingBase64Gob: base64GobCodec{},
+		EncodingGob:       gobCodec{},
+		EncodingProto:     protoCodec{},
+		EncodingNone:      noneCodec{},
+		EncodingJSON:      jsonCodec{},
+	}
+	codecMutex sync.RWMutex
+)
+
+// RegisterCodec adds or replaces the codec of the encoding, a new format takes an
+// Encoding value of its own, e.g. EncodingMsgPack Encoding = 100. The content type of
+// the codec must be unique, the consumers pick the codec of a message with it.
+func RegisterCodec(enc Encoding, codec Codec) {
+	codecMutex.Lock()
+	defer codecMutex.Unlock()
+
+	if codec == nil {
+		delete(codecs, enc)
+		return
+	}
+	codecs[enc] = codec
+}
+
+// CodecOf returns the codec registered for the encoding.
+func CodecOf(enc Encoding) (Codec, bool) {
+	codecMutex.RLock()
+	defer codecMutex.RUnlock()
+
+	codec, ok := codecs[enc]
+	return codec, ok
+}
+
+// CodecByContentType returns the codec registered with the content type.
+func CodecByContentType(contentType string) (Codec, bool) {
+	if len(contentType) == 0 {
+		return nil, false
+	}
+
+	codecMutex.RLock()
+	defer codecMutex.RUnlock()
+
+	for _, codec := range codecs {
+		if codec.ContentType() == contentType {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+// ContentTypeOf returns the content type of the codec of the encoding, empty when no
+// codec is registered.
+func ContentTypeOf(enc Encoding) string {
+	if codec, ok := CodecOf(enc); ok {
+		return codec.ContentType()
+	}
+	return ""
+}
+
+type base64GobCodec struct{}
+
+func (base64GobCodec) ContentType() string {
+	return "application/x-gob+base64"
+}
+
+func (base64GobCodec) Encode(id string, data interface{}) ([]byte, error) {
+	raw, err := gobCodec{}.Encode(id, data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(raw)), nil
+}
+
+func (base64GobCodec) Decode(raw []byte, data interface{}) error {
+	return gob.NewDecoder(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(raw))).Decode(data)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) ContentType() string {
+	return "application/x-gob"
+}
+
+func (gobCodec) Encode(id string, data interface{}) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	if err := gob.NewEncoder(buffer).Encode(data); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (gobCodec) Decode(raw []byte, data interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(raw)).Decode(data)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Encode(id string, data interface{}) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+func (jsonCodec) Decode(raw []byte, data interface{}) error {
+	return json.Unmarshal(raw, data)
+}
+
+// noneCodec sends a string or a []byte as it is.
+type noneCodec struct{}
+
+func (noneCodec) ContentType() string {
+	return "application/octet-stream"
+}
+
+func (noneCodec) Encode(id string, data interface{}) ([]byte, error) {
+	switch val := data.(type) {
+	case string:
+		return []byte(val), nil
+	case []byte:
+		return val, nil
+	}
+	return nil, fmt.Errorf("encoding none: can not encode %T, want string or []byte", data)
+}
+
+func (noneCodec) Decode(raw []byte, data interface{}) error {
+	switch val := data.(type) {
+	case *string:
+		*val = string(raw)
+	case *[]byte:
+		*val = raw
+	default:
+		return fmt.Errorf("encoding none: can not decode into %T, want *string or *[]byte", data)
+	}
+	return nil
+}
+
+// protoCodec wraps a proto.Message in a SendData envelope with the id.
+type protoCodec struct{}
+
+func (protoCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (protoCodec) Encode(id string, data interface{}) ([]byte, error) {
+	val, ok := data.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("encoding proto: can not encode %T, want a proto.Message", data)
+	}
+	any, err := anypb.New(val)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&databaseproto.SendData{ID: id, Data: any})
+}
+
+func (protoCodec) Decode(raw []byte, data interface{}) error {
+	envelope := &databaseproto.SendData{}
+	if err := proto.Unmarshal(raw, envelope); err != nil {
+		return fmt.Errorf("encoding proto: %w", err)
+	}
+
+	switch val := data.(type) {
+	case *databaseproto.SendData:
+		proto.Reset(val)
+		proto.Merge(val, envelope)
+		return nil
+	case proto.Message:
+		if envelope.Data == nil {
+			return fmt.Errorf("encoding proto: empty envelope %s", envelope.ID)
+		}
+		return envelope.Data.UnmarshalTo(val)
+	}
+	return fmt.Errorf("encoding proto: can not decode into %T, want a proto.Message", data)
+}
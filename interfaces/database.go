@@ -5,11 +5,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"io"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/gorm"
 )
 
@@ -17,9 +19,27 @@ type Database interface {
 	Init(logger logger.Logger)
 	LoadElasticSearch(string, ElasticSearchProviderConfig) ElasticSearch
 	LoadSQLDatabase(config SQLConfig) SQL
+	LoadSQLDatabaseWithTag(tag string, config SQLConfig) SQL
 	LoadRedisDatabase(config RedisProviderConfig) Redis
+	LoadRedisDatabaseWithTag(tag string, config RedisProviderConfig) Redis
 	LoadMongoDatabase(config MongoProviderConfig) Mongo
+	LoadMongoDatabaseWithTag(tag string, config MongoProviderConfig) Mongo
 	LoadRabbitMQ(tag string, config RabbitMQProviderConfig) RabbitMQ
+	LoadKafka(tag string, config KafkaProviderConfig) Kafka
+	LoadKafkaByTag(tag string) (Kafka, bool)
+	LoadFromFile(path string) error
+	LoadFromYAML(r io.Reader) error
+	LoadFromConfig(config *Config) error
+	GetSQL(tag string) (SQL, bool)
+	GetRedis(tag string) (Redis, bool)
+	GetMongo(tag string) (Mongo, bool)
+	GetElasticSearch(tag string) (ElasticSearch, bool)
+	GetRabbitMQ(tag string) (RabbitMQ, bool)
+	GetKafka(tag string) (Kafka, bool)
+	List() []ClientInfo
+	Health(ctx context.Context) HealthReport
+	Shutdown(ctx context.Context) error
+	EnableMetrics(reg prometheus.Registerer) error
 }
 
 type ElasticSearch interface {
@@ -29,6 +49,8 @@ type ElasticSearch interface {
 	CreateIndex(name string, mapping string) error
 	Create(index string, id string, values interface{}) error
 	Delete(id string) error
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
 }
 
 type Redis interface {
@@ -36,40 +58,71 @@ type Redis interface {
 	GetPool() *redis.PoolStats
 	Set(context.Context, string, interface{}, time.Duration) error
 	Get(context.Context, string) (string, error)
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
 }
 
 type SQL interface {
 	Orm() *gorm.DB
 	MySQL() error
 	LoadSQL() error
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
 }
 
 type Mongo interface {
 	Init() error
+	// SetDatabase returns nil when the client is not connected.
 	SetDatabase(db string) *mongo.Database
 	LoadPostChannel(ctx context.Context, db, table string, filter bson.M, res chan<- []bson.M, opt ...*options.FindOptions)
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
 }
 
 type Kafka interface {
 	Consumer(KafkaOptions, ConsumerCallback)
+	Handle(KafkaOptions, ConsumerHandler)
 	Producer(ProducerIsReady)
 	Push(ctx context.Context, id string, options KafkaOptions, body interface{}, cb ConsumerCallback) error
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
 }
 
 type RabbitMQ interface {
 	Consumer(RabbitMQOptions, ConsumerCallback)
+	Handle(RabbitMQOptions, ConsumerHandler)
 	Producer(RabbitMQOptions)
 	Push(ctx context.Context,
 		id, key string,
 		body interface{},
 		cb ConsumerCallback) error
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
 }
 
+// ConsumerCallbackIsDone is handed to a consumer callback with its message, the
+// Acknowledger is only set for the consumers with RabbitMQOptions.ManualAck.
 type ConsumerCallbackIsDone struct {
-	Done       context.CancelFunc
-	EndRequest func()
+	Done         context.CancelFunc
+	EndRequest   func()
+	Acknowledger Acknowledger
 }
 
 type ConsumerCallback func(Messages, ConsumerCallbackIsDone)
 
+// ConsumerMiddleware wraps a consumer callback, e.g. to log, time or recover it.
+type ConsumerMiddleware func(ConsumerCallback) ConsumerCallback
+
 type ProducerIsReady func()
+
+// SecretProvider resolves the reference of a config value written scheme:reference,
+// e.g. vault:secret/db#password, each time the client connects.
+type SecretProvider interface {
+	Resolve(ctx context.Context, reference string) (string, error)
+}
+
+type SecretProviderFunc func(ctx context.Context, reference string) (string, error)
+
+func (f SecretProviderFunc) Resolve(ctx context.Context, reference string) (string, error) {
+	return f(ctx, reference)
+}
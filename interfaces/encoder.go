@@ -3,27 +3,66 @@ package interfaces
 import (
 	"bytes"
 	"context"
-	"encoding/gob"
-	"encoding/json"
 	"fmt"
 	"io"
-	"reflect"
+	"sync"
+	"time"
 )
 
+// Encoder decodes the body of a received message with the codec of its content type,
+// or with the encoding of its consumer when the message has none, the producers write
+// the body with Encode so every encoding round-trips.
 type Encoder struct {
-	Raw        io.Reader
-	Encoding   Encoding
-	exchange   string
-	routingKey string
-	context    context.Context
+	Raw         io.Reader
+	Encoding    Encoding
+	ContentType string
+	MessageID   string
+	info        MessageInfo
+	body        []byte
+	read        sync.Once
+	readErr     error
+	exchange    string
+	routingKey  string
+	context     context.Context
+}
+
+// MessageInfo is what a consumer knows about a received message besides its body.
+type MessageInfo struct {
+	ID           string
+	Headers      map[string]interface{}
+	Timestamp    time.Time
+	Redeliveries int
+	Metadata     MessageMetadata
 }
 
 func NewEncoder(raw io.Reader, exchange, routingKey string, enc Encoding) Messages {
+	return NewEncoderWithContentType(raw, exchange, routingKey, enc, "")
+}
+
+// NewEncoderWithContentType returns an Encoder picking the codec from the content-type
+// header of the message, enc is the fallback.
+func NewEncoderWithContentType(raw io.Reader, exchange, routingKey string, enc Encoding, contentType string) Messages {
+	return &Encoder{
+		Raw:         raw,
+		Encoding:    enc,
+		ContentType: contentType,
+		exchange:    exchange,
+		routingKey:  routingKey,
+	}
+}
+
+// NewMessage returns the Encoder of a received body, the content type is the one of
+// the content-type header.
+func NewMessage(body []byte, exchange, routingKey string, enc Encoding, info MessageInfo) Messages {
+	contentType, _ := info.Headers[HeaderContentType].(string)
 	return &Encoder{
-		Raw:        raw,
-		Encoding:   enc,
-		exchange:   exchange,
-		routingKey: routingKey,
+		Raw:         bytes.NewReader(body),
+		Encoding:    enc,
+		ContentType: contentType,
+		MessageID:   info.ID,
+		info:        info,
+		exchange:    exchange,
+		routingKey:  routingKey,
 	}
 }
 
@@ -35,51 +74,54 @@ func (c *Encoder) Context() context.Context {
 	return c.context
 }
 
+// bytes reads the body once, Body and Decode may both be called.
+func (c *Encoder) bytes() ([]byte, error) {
+	c.read.Do(func() {
+		if c.Raw != nil {
+			c.body, c.readErr = io.ReadAll(c.Raw)
+		}
+	})
+	return c.body, c.readErr
+}
+
+// Decode reads the body into data, a pointer. EncodingNone fills a string or a
+// []byte, EncodingProto unwraps the SendData envelope into a proto.Message, or
+// copies it when data is the envelope itself.
 func (c *Encoder) Decode(data interface{}) error {
-	ref := reflect.ValueOf(data).Elem()
-	switch c.Encoding {
-	case EncodingNone:
-		bys := bytes.NewBuffer(nil)
-		if _, err := io.Copy(bys, c.Raw); err == nil {
-			switch ref.Kind() {
-			case reflect.String:
-				ref.SetString(bys.String())
-			}
+	codec, ok := CodecByContentType(c.ContentType)
+	if !ok {
+		if codec, ok = CodecOf(c.Encoding); !ok {
+			return fmt.Errorf("encoding %d not supported", c.Encoding)
 		}
-		return nil
-	case EncodingGob:
-		return gob.NewDecoder(c.Raw).Decode(data)
-	case EncodingBase64Gob:
-		return gob.NewDecoder(c.Raw).Decode(data)
-	case EncodingJSON:
-		return json.NewDecoder(c.Raw).Decode(data)
 	}
-	return fmt.Errorf("encoding not supported")
-}
-
-func (c *Encoder) Encode(data interface{}) (res []byte, err error) {
-	ref := reflect.TypeOf(data)
-	switch c.Encoding {
-	case EncodingNone:
-		switch ref.Kind() {
-		case reflect.Slice:
-			if val, ok := data.([]byte); ok {
-				return val, nil
-			}
-			return nil, fmt.Errorf("failed to covertion type data slice")
-		case reflect.String:
-			return []byte(data.(string)), nil
-		}
-	case EncodingGob:
-		buffer := bytes.NewBuffer(nil)
-		defer buffer.Reset()
-		if err := gob.NewEncoder(buffer).Encode(data); err != nil {
-			return res, err
-		}
-		return buffer.Bytes(), nil
+
+	raw, err := c.bytes()
+	if err != nil {
+		return err
+	}
+	return codec.Decode(raw, data)
+}
+
+// Encode writes data with the encoding of the encoder, the MessageID is the one of
+// the proto envelope.
+func (c *Encoder) Encode(data interface{}) ([]byte, error) {
+	return Encode(c.Encoding, c.MessageID, data)
+}
+
+// Encode writes data with the codec registered for the encoding, as the producers of
+// the library send it. EncodingNone takes a string or a []byte, EncodingProto a
+// proto.Message wrapped in a SendData envelope with the id, other values return an
+// error.
+func Encode(enc Encoding, id string, data interface{}) ([]byte, error) {
+	codec, ok := CodecOf(enc)
+	if !ok {
+		return nil, fmt.Errorf("encoding %d not supported", enc)
 	}
+	return codec.Encode(id, data)
+}
 
-	return res, fmt.Errorf("encoding not supported")
+func (c *Encoder) ID() string {
+	return c.MessageID
 }
 
 func (c *Encoder) Exchange() string {
@@ -89,3 +131,25 @@ func (c *Encoder) Exchange() string {
 func (c *Encoder) RoutingKey() string {
 	return c.routingKey
 }
+
+func (c *Encoder) Headers() map[string]interface{} {
+	return c.info.Headers
+}
+
+// Body returns the raw body, nil when it can not be read.
+func (c *Encoder) Body() []byte {
+	body, _ := c.bytes()
+	return body
+}
+
+func (c *Encoder) Timestamp() time.Time {
+	return c.info.Timestamp
+}
+
+func (c *Encoder) Redeliveries() int {
+	return c.info.Redeliveries
+}
+
+func (c *Encoder) Metadata() MessageMetadata {
+	return c.info.Metadata
+}
@@ -0,0 +1,398 @@
+package interfacestest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Broker adapts a RabbitMQ or Kafka client to RunBrokerSuite. A destination is the
+// exchange of a RabbitMQ consumer and the key of its push, or a Kafka topic.
+type Broker struct {
+	Consume func(destination string, enc database.Encoding, cb database.ConsumerCallback)
+	Handle  func(destination string, enc database.Encoding, policy *database.RetryPolicy, handler database.ConsumerHandler)
+	Push    func(ctx context.Context, destination string, enc database.Encoding, body interface{}) error
+	Close   func(ctx context.Context) error
+}
+
+// BrokerFactory returns a connected client, the suite closes it.
+type BrokerFactory func(t *testing.T) Broker
+
+// RabbitMQBroker adapts the client, the producer is created with the encoding of
+// the first push.
+func RabbitMQBroker(client database.RabbitMQ) Broker {
+	var once sync.Once
+	return Broker{
+		Consume: func(destination string, enc database.Encoding, cb database.ConsumerCallback) {
+			client.Consumer(database.RabbitMQOptions{Exchange: destination, Encoding: enc}, cb)
+		},
+		Handle: func(destination string, enc database.Encoding, policy *database.RetryPolicy, handler database.ConsumerHandler) {
+			client.Handle(database.RabbitMQOptions{Exchange: destination, Encoding: enc, Retry: policy}, handler)
+		},
+		Push: func(ctx context.Context, destination string, enc database.Encoding, body interface{}) error {
+			once.Do(func() {
+				client.Producer(database.RabbitMQOptions{Encoding: enc})
+			})
+			return client.Push(ctx, "", destination, body, nil)
+		},
+		Close: client.Close,
+	}
+}
+
+// KafkaBroker adapts the client, the consumers join the group named interfacestest.
+func KafkaBroker(client database.Kafka) Broker {
+	var once sync.Once
+	return Broker{
+		Consume: func(destination string, enc database.Encoding, cb database.ConsumerCallback) {
+			client.Consumer(database.KafkaOptions{Topic: destination, Group: "interfacestest", Encoding: enc}, cb)
+		},
+		Handle: func(destination string, enc database.Encoding, policy *database.RetryPolicy, handler database.ConsumerHandler) {
+			client.Handle(database.KafkaOptions{Topic: destination, Group: "interfacestest", Encoding: enc, Retry: policy}, handler)
+		},
+		Push: func(ctx context.Context, destination string, enc database.Encoding, body interface{}) error {
+			once.Do(func() {
+				client.Producer(nil)
+			})
+			return client.Push(ctx, "", database.KafkaOptions{Topic: destination, Encoding: enc}, body, nil)
+		},
+		Close: client.Close,
+	}
+}
+
+func RunRabbitMQSuite(t *testing.T, factory func(t *testing.T) database.RabbitMQ, hooks ...Hooks) {
+	RunBrokerSuite(t, func(t *testing.T) Broker {
+		return RabbitMQBroker(factory(t))
+	}, hooks...)
+
+	h := hooksOf(hooks)
+
+	// a handler error nacks the delivery, which is requeued and redelivered
+	t.Run("ManualAck", func(t *testing.T) {
+		client := factory(t)
+		closeOnCleanup(t, h.Timeout, client.Close)
+		destination := unique("interfacestest-")
+
+		received := make(chan database.Messages, 2)
+		client.Handle(database.RabbitMQOptions{
+			Exchange:       destination,
+			Encoding:       database.EncodingJSON,
+			ManualAck:      true,
+			RequeueOnError: true,
+		}, func(msg database.Messages, done database.ConsumerCallbackIsDone) error {
+			received <- msg
+			if msg.Redeliveries() == 0 {
+				return fmt.Errorf("first delivery")
+			}
+			return nil
+		})
+
+		client.Producer(database.RabbitMQOptions{Encoding: database.EncodingJSON})
+		eventually(t, h.Timeout, func() error {
+			return client.Push(context.Background(), "", destination, Payload{"manual-ack", 1}, nil)
+		})
+
+		first := receive(t, h, received)
+		second := receive(t, h, received)
+		if first.ID() != second.ID() {
+			t.Errorf("redelivered ID = %q, want %q", second.ID(), first.ID())
+		}
+		if second.Redeliveries() == 0 {
+			t.Errorf("Redeliveries = 0 after a nack")
+		}
+	})
+}
+
+func RunKafkaSuite(t *testing.T, factory func(t *testing.T) database.Kafka, hooks ...Hooks) {
+	RunBrokerSuite(t, func(t *testing.T) Broker {
+		return KafkaBroker(factory(t))
+	}, hooks...)
+}
+
+// Payload is the body pushed by the broker suite.
+type Payload struct {
+	ID    string
+	Count int
+}
+
+// roundTrip pushes body and expects decode to return want, or body when want is nil.
+type roundTrip struct {
+	name     string
+	encoding database.Encoding
+	body     interface{}
+	want     interface{}
+	decode   func(msg database.Messages) (interface{}, error)
+}
+
+func roundTrips() []roundTrip {
+	decodePayload := func(msg database.Messages) (interface{}, error) {
+		var val Payload
+		err := msg.Decode(&val)
+		return val, err
+	}
+
+	return []roundTrip{
+		{"Base64Gob", database.EncodingBase64Gob, Payload{"base64gob", 1}, nil, decodePayload},
+		{"Gob", database.EncodingGob, Payload{"gob", 2}, nil, decodePayload},
+		{"JSON", database.EncodingJSON, Payload{"json", 3}, nil, decodePayload},
+		{"None", database.EncodingNone, "plain text", nil, func(msg database.Messages) (interface{}, error) {
+			var val string
+			err := msg.Decode(&val)
+			return val, err
+		}},
+		{"NoneBytes", database.EncodingNone, []byte("raw bytes"), nil, func(msg database.Messages) (interface{}, error) {
+			var val []byte
+			err := msg.Decode(&val)
+			return val, err
+		}},
+		{"Proto", database.EncodingProto, wrapperspb.String("proto"), "proto", func(msg database.Messages) (interface{}, error) {
+			var val wrapperspb.StringValue
+			err := msg.Decode(&val)
+			return val.GetValue(), err
+		}},
+	}
+}
+
+// RunBrokerSuite checks that a push reaches the consumer of its destination and is
+// decoded back to the same value for every encoding, and that the consumer still
+// receives the messages once the client reconnected.
+func RunBrokerSuite(t *testing.T, factory BrokerFactory, hooks ...Hooks) {
+	h := hooksOf(hooks)
+
+	newBroker := func(t *testing.T) Broker {
+		broker := factory(t)
+		closeOnCleanup(t, h.Timeout, broker.Close)
+		return broker
+	}
+
+	// push retries until the client accepts the message, a producer may need to
+	// connect first.
+	push := func(t *testing.T, broker Broker, destination string, enc database.Encoding, body interface{}) {
+		t.Helper()
+		eventually(t, h.Timeout, func() error {
+			return broker.Push(context.Background(), destination, enc, body)
+		})
+	}
+
+	for _, tt := range roundTrips() {
+		tt := tt
+		t.Run("RoundTrip/"+tt.name, func(t *testing.T) {
+			broker := newBroker(t)
+			destination := unique("interfacestest-")
+
+			received := make(chan database.Messages, 1)
+			broker.Consume(destination, tt.encoding, func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+				received <- msg
+			})
+
+			push(t, broker, destination, tt.encoding, tt.body)
+
+			msg := receive(t, h, received)
+			got, err := tt.decode(msg)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			want := tt.want
+			if want == nil {
+				want = tt.body
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decode = %#v, want %#v", got, want)
+			}
+			if msg.Exchange() != destination {
+				t.Errorf("Exchange = %q, want %q", msg.Exchange(), destination)
+			}
+			if msg.Context() == nil {
+				t.Errorf("Context is nil")
+			}
+		})
+	}
+
+	// the content-type header picks the codec whatever the encoding of the consumer
+	t.Run("ContentType", func(t *testing.T) {
+		broker := newBroker(t)
+		destination := unique("interfacestest-")
+
+		received := make(chan database.Messages, 1)
+		broker.Consume(destination, database.EncodingGob, func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			received <- msg
+		})
+
+		body := Payload{"content-type", 1}
+		push(t, broker, destination, database.EncodingJSON, body)
+
+		var val Payload
+		if err := receive(t, h, received).Decode(&val); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		if val != body {
+			t.Errorf("Decode = %#v, want %#v", val, body)
+		}
+	})
+
+	// a body the codec can not encode is not sent
+	t.Run("EncodeError", func(t *testing.T) {
+		broker := newBroker(t)
+		destination := unique("interfacestest-")
+
+		if err := broker.Push(context.Background(), destination, database.EncodingNone, Payload{"encode-error", 1}); err == nil {
+			t.Errorf("Push of a struct with EncodingNone succeeded")
+		}
+	})
+
+	t.Run("Message", func(t *testing.T) {
+		broker := newBroker(t)
+		destination := unique("interfacestest-")
+
+		received := make(chan database.Messages, 1)
+		broker.Consume(destination, database.EncodingJSON, func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			received <- msg
+		})
+
+		push(t, broker, destination, database.EncodingNone, "raw body")
+
+		msg := receive(t, h, received)
+		if len(msg.ID()) == 0 {
+			t.Errorf("ID is empty")
+		}
+		if got := string(msg.Body()); got != "raw body" {
+			t.Errorf("Body = %q, want %q", got, "raw body")
+		}
+		if got, want := msg.Headers()[database.HeaderContentType], database.ContentTypeOf(database.EncodingNone); got != want {
+			t.Errorf("Headers[%s] = %v, want %s", database.HeaderContentType, got, want)
+		}
+		if msg.Timestamp().IsZero() {
+			t.Errorf("Timestamp is zero")
+		}
+		if metadata := msg.Metadata(); metadata.Kafka == nil && metadata.AMQP == nil {
+			t.Errorf("Metadata is empty")
+		}
+
+		var val string
+		if err := msg.Decode(&val); err != nil || val != "raw body" {
+			t.Errorf("Decode after Body = %q, %v", val, err)
+		}
+	})
+
+	// a message failing every attempt goes to the dead letter destination
+	t.Run("RetryDeadLetter", func(t *testing.T) {
+		broker := newBroker(t)
+		if broker.Handle == nil {
+			t.Skip("no Handle")
+		}
+		destination := unique("interfacestest-")
+
+		attempts := make(chan database.Messages, 10)
+		broker.Handle(destination, database.EncodingJSON, &database.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff:     database.BackoffConfig{InitialInterval: 10, Jitter: -1},
+		}, func(msg database.Messages, done database.ConsumerCallbackIsDone) error {
+			attempts <- msg
+			return fmt.Errorf("always failing")
+		})
+
+		deadLetters := make(chan database.Messages, 1)
+		broker.Consume(destination+".dlq", database.EncodingJSON, func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			deadLetters <- msg
+		})
+
+		body := Payload{"dead-letter", 1}
+		push(t, broker, destination, database.EncodingJSON, body)
+
+		first := receive(t, h, attempts)
+		receive(t, h, attempts)
+
+		msg := receive(t, h, deadLetters)
+		var val Payload
+		if err := msg.Decode(&val); err != nil || val != body {
+			t.Errorf("Decode = %#v, %v, want %#v", val, err, body)
+		}
+		if msg.ID() != first.ID() {
+			t.Errorf("ID = %q, want %q", msg.ID(), first.ID())
+		}
+		if got := msg.Headers()[database.HeaderDeadLetterReason]; got != "always failing" {
+			t.Errorf("Headers[%s] = %v", database.HeaderDeadLetterReason, got)
+		}
+		if got := msg.Headers()[database.HeaderRetryAttempt]; got != "2" {
+			t.Errorf("Headers[%s] = %v, want 2", database.HeaderRetryAttempt, got)
+		}
+	})
+
+	t.Run("EveryMessage", func(t *testing.T) {
+		broker := newBroker(t)
+		destination := unique("interfacestest-")
+
+		received := make(chan database.Messages, 10)
+		broker.Consume(destination, database.EncodingJSON, func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			received <- msg
+		})
+
+		want := make(map[string]bool)
+		for i := 0; i < 10; i++ {
+			body := Payload{fmt.Sprintf("message-%d", i), i}
+			want[body.ID] = true
+			push(t, broker, destination, database.EncodingJSON, body)
+		}
+
+		for len(want) != 0 {
+			var val Payload
+			if err := receive(t, h, received).Decode(&val); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !want[val.ID] {
+				t.Fatalf("unexpected or duplicated message %q", val.ID)
+			}
+			delete(want, val.ID)
+		}
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		if h.Reconnect == nil {
+			t.Skip("no Reconnect hook")
+		}
+
+		broker := newBroker(t)
+		destination := unique("interfacestest-")
+
+		received := make(chan database.Messages, 10)
+		broker.Consume(destination, database.EncodingJSON, func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			received <- msg
+		})
+
+		push(t, broker, destination, database.EncodingJSON, Payload{"before", 1})
+		receive(t, h, received)
+
+		h.Reconnect(t)
+
+		// the message received before may be redelivered once reconnected
+		push(t, broker, destination, database.EncodingJSON, Payload{"after", 2})
+		for {
+			var val Payload
+			if err := receive(t, h, received).Decode(&val); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if val.ID == "after" {
+				return
+			}
+		}
+	})
+}
+
+func receive(t *testing.T, h Hooks, received <-chan database.Messages) database.Messages {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.Timeout)
+	defer cancel()
+
+	select {
+	case msg := <-received:
+		return msg
+	case <-ctx.Done():
+		t.Fatalf("no message received after %s", h.Timeout)
+		return nil
+	}
+}
@@ -0,0 +1,134 @@
+package interfacestest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+// ElasticSearchFactory returns a connected client whose IndexName is the given
+// index, the suite closes it.
+type ElasticSearchFactory func(t *testing.T, index string) database.ElasticSearch
+
+// Document is the value indexed by the elasticsearch suite.
+type Document struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// RunElasticSearchSuite checks that created documents are searched back, that a
+// duplicated id is refused and that a deleted document is gone.
+func RunElasticSearchSuite(t *testing.T, factory ElasticSearchFactory, hooks ...Hooks) {
+	h := hooksOf(hooks)
+
+	newClient := func(t *testing.T) (database.ElasticSearch, string) {
+		index := unique("interfacestest-")
+		client := factory(t, index)
+		closeOnCleanup(t, h.Timeout, client.Close)
+
+		if err := client.CreateIndex(index, `{}`); err != nil {
+			t.Fatalf("CreateIndex: %v", err)
+		}
+		return client, index
+	}
+
+	// search retries until the documents are visible, elasticsearch refreshes the
+	// index every second.
+	search := func(t *testing.T, client database.ElasticSearch, options database.ElasticSearchOptions, want int) *database.SearchResultsElasticSearch {
+		t.Helper()
+
+		var result *database.SearchResultsElasticSearch
+		eventually(t, h.Timeout, func() error {
+			res, err := client.Search(options)
+			if err != nil {
+				return err
+			}
+			if len(res.Hits) != want {
+				return fmt.Errorf("search %q returned %d hits, want %d", options.Query, len(res.Hits), want)
+			}
+			result = res
+			return nil
+		})
+		return result
+	}
+
+	t.Run("Ping", func(t *testing.T) {
+		client, _ := newClient(t)
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping: %v", err)
+		}
+	})
+
+	t.Run("CreateIndexTwice", func(t *testing.T) {
+		client, index := newClient(t)
+		if err := client.CreateIndex(index, `{}`); err == nil {
+			t.Errorf("CreateIndex of an existing index returned no error")
+		}
+	})
+
+	t.Run("CreateSearch", func(t *testing.T) {
+		client, index := newClient(t)
+
+		for id, doc := range map[string]Document{
+			"1": {"first document", "hello world"},
+			"2": {"second document", "hello there"},
+			"3": {"third document", "goodbye"},
+		} {
+			if err := client.Create(index, id, doc); err != nil {
+				t.Fatalf("Create(%s): %v", id, err)
+			}
+		}
+
+		search(t, client, database.ElasticSearchOptions{}, 3)
+		search(t, client, database.ElasticSearchOptions{Query: "hello"}, 2)
+		search(t, client, database.ElasticSearchOptions{Query: "hello world"}, 1)
+		search(t, client, database.ElasticSearchOptions{Size: 2}, 2)
+	})
+
+	t.Run("CreateConflict", func(t *testing.T) {
+		client, index := newClient(t)
+
+		if err := client.Create(index, "1", Document{"title", "body"}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := client.Create(index, "1", Document{"title", "body"}); !dberrors.Is(err, dberrors.ErrConflict) {
+			t.Errorf("Create of an existing id = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		client, index := newClient(t)
+
+		if err := client.Create(index, "1", Document{"title", "body"}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		search(t, client, database.ElasticSearchOptions{}, 1)
+
+		if res, err := client.Search(database.ElasticSearchOptions{Query: "missing"}); !dberrors.Is(err, dberrors.ErrNotFound) {
+			t.Errorf("Search without hits = %v, %v, want ErrNotFound", res, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		client, index := newClient(t)
+
+		for _, id := range []string{"1", "2"} {
+			if err := client.Create(index, id, Document{"title " + id, "body"}); err != nil {
+				t.Fatalf("Create(%s): %v", id, err)
+			}
+		}
+		search(t, client, database.ElasticSearchOptions{}, 2)
+
+		if err := client.Delete("1"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		search(t, client, database.ElasticSearchOptions{}, 1)
+
+		if err := client.Delete("1"); !dberrors.Is(err, dberrors.ErrNotFound) {
+			t.Errorf("Delete of a missing document = %v, want ErrNotFound", err)
+		}
+	})
+}
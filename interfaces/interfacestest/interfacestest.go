@@ -0,0 +1,68 @@
+// Package interfacestest provides conformance suites checking an implementation of
+// the interfaces package against the contract expected by the library, for the
+// clients of the lib package as well as for fakes.
+package interfacestest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fajarardiyanto/flt-go-utils/hash"
+)
+
+// DefaultTimeout bounds every wait of a suite when Hooks.Timeout is zero.
+const DefaultTimeout = 10 * time.Second
+
+// Hooks tunes a suite to the implementation under test.
+type Hooks struct {
+	// Timeout bounds every wait of the suite.
+	Timeout time.Duration
+	// Reconnect breaks the connection of the client under test, e.g. by restarting
+	// the server, and returns once it is broken. The reconnection tests are skipped
+	// when it is nil.
+	Reconnect func(t *testing.T)
+}
+
+func hooksOf(hooks []Hooks) Hooks {
+	var h Hooks
+	if len(hooks) != 0 {
+		h = hooks[0]
+	}
+	if h.Timeout <= 0 {
+		h.Timeout = DefaultTimeout
+	}
+	return h
+}
+
+func unique(prefix string) string {
+	return prefix + hash.CreateRandomId(10)
+}
+
+// eventually calls cond until it returns nil or the timeout expires, then fails
+// with the last error.
+func eventually(t *testing.T, timeout time.Duration, cond func() error) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := cond()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met after %s: %v", timeout, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func closeOnCleanup(t *testing.T, timeout time.Duration, close func(ctx context.Context) error) {
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := close(ctx); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+}
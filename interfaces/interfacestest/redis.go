@@ -0,0 +1,150 @@
+package interfacestest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+// RedisFactory returns an initialized client, the suite closes it.
+type RedisFactory func(t *testing.T) database.Redis
+
+// RunRedisSuite checks the Set/Get semantics of the client: the values are read back
+// as strings, a missing or expired key is ErrNotFound and a zero TTL never expires.
+func RunRedisSuite(t *testing.T, factory RedisFactory, hooks ...Hooks) {
+	h := hooksOf(hooks)
+
+	newClient := func(t *testing.T) database.Redis {
+		client := factory(t)
+		closeOnCleanup(t, h.Timeout, client.Close)
+		return client
+	}
+
+	t.Run("Ping", func(t *testing.T) {
+		client := newClient(t)
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping: %v", err)
+		}
+	})
+
+	t.Run("SetGet", func(t *testing.T) {
+		client := newClient(t)
+		ctx := context.Background()
+
+		for _, tt := range []struct {
+			value interface{}
+			want  string
+		}{
+			{"value", "value"},
+			{[]byte("raw"), "raw"},
+			{42, "42"},
+			{1.5, "1.5"},
+			{true, "1"},
+		} {
+			key := unique("interfacestest:")
+			if err := client.Set(ctx, key, tt.value, 0); err != nil {
+				t.Fatalf("Set(%#v): %v", tt.value, err)
+			}
+
+			got, err := client.Get(ctx, key)
+			if err != nil {
+				t.Fatalf("Get(%#v): %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		client := newClient(t)
+		ctx := context.Background()
+		key := unique("interfacestest:")
+
+		if err := client.Set(ctx, key, "first", 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if err := client.Set(ctx, key, "second", 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		if got, err := client.Get(ctx, key); err != nil || got != "second" {
+			t.Errorf("Get = %q, %v, want %q", got, err, "second")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		client := newClient(t)
+
+		got, err := client.Get(context.Background(), unique("interfacestest:missing:"))
+		if !dberrors.Is(err, dberrors.ErrNotFound) {
+			t.Fatalf("Get of a missing key = %q, %v, want ErrNotFound", got, err)
+		}
+		if got != "" {
+			t.Errorf("Get of a missing key = %q, want empty", got)
+		}
+	})
+
+	t.Run("Expiration", func(t *testing.T) {
+		client := newClient(t)
+		ctx := context.Background()
+		key := unique("interfacestest:")
+
+		if err := client.Set(ctx, key, "value", 200*time.Millisecond); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if got, err := client.Get(ctx, key); err != nil || got != "value" {
+			t.Fatalf("Get before expiration = %q, %v", got, err)
+		}
+
+		eventually(t, h.Timeout, func() error {
+			got, err := client.Get(ctx, key)
+			if err == nil {
+				return fmt.Errorf("key still holds %q", got)
+			}
+			if !dberrors.Is(err, dberrors.ErrNotFound) {
+				return fmt.Errorf("Get of an expired key = %v, want ErrNotFound", err)
+			}
+			return nil
+		})
+	})
+
+	t.Run("NoExpiration", func(t *testing.T) {
+		client := newClient(t)
+		ctx := context.Background()
+		key := unique("interfacestest:")
+
+		if err := client.Set(ctx, key, "value", 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		time.Sleep(300 * time.Millisecond)
+
+		if got, err := client.Get(ctx, key); err != nil || got != "value" {
+			t.Errorf("Get = %q, %v, want %q", got, err, "value")
+		}
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		if h.Reconnect == nil {
+			t.Skip("no Reconnect hook")
+		}
+
+		client := newClient(t)
+		ctx := context.Background()
+		key := unique("interfacestest:")
+
+		h.Reconnect(t)
+
+		eventually(t, h.Timeout, func() error {
+			if err := client.Set(ctx, key, "value", 0); err != nil {
+				return err
+			}
+			_, err := client.Get(ctx, key)
+			return err
+		})
+	})
+}
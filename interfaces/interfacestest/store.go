@@ -0,0 +1,91 @@
+package interfacestest
+
+import (
+	"context"
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RunMongoSuite checks that an initialized client answers and that a query on an
+// empty collection sends an empty result.
+func RunMongoSuite(t *testing.T, factory func(t *testing.T) database.Mongo, hooks ...Hooks) {
+	h := hooksOf(hooks)
+
+	newClient := func(t *testing.T) database.Mongo {
+		client := factory(t)
+		closeOnCleanup(t, h.Timeout, client.Close)
+		return client
+	}
+
+	t.Run("Ping", func(t *testing.T) {
+		client := newClient(t)
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping: %v", err)
+		}
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		client := newClient(t)
+
+		res := make(chan []bson.M, 1)
+		go client.LoadPostChannel(context.Background(), "interfacestest", unique("empty"), bson.M{}, res)
+
+		ctx, cancel := context.WithTimeout(context.Background(), h.Timeout)
+		defer cancel()
+
+		select {
+		case docs := <-res:
+			if len(docs) != 0 {
+				t.Errorf("LoadPostChannel returned %d documents, want 0", len(docs))
+			}
+		case <-ctx.Done():
+			t.Fatalf("LoadPostChannel sent no result after %s", h.Timeout)
+		}
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		if h.Reconnect == nil {
+			t.Skip("no Reconnect hook")
+		}
+
+		client := newClient(t)
+		h.Reconnect(t)
+
+		eventually(t, h.Timeout, func() error {
+			return client.Ping(context.Background())
+		})
+	})
+}
+
+// RunSQLSuite checks that a loaded client exposes its gorm session and answers.
+func RunSQLSuite(t *testing.T, factory func(t *testing.T) database.SQL, hooks ...Hooks) {
+	h := hooksOf(hooks)
+
+	t.Run("Orm", func(t *testing.T) {
+		client := factory(t)
+		closeOnCleanup(t, h.Timeout, client.Close)
+
+		if client.Orm() == nil {
+			t.Fatalf("Orm is nil")
+		}
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping: %v", err)
+		}
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		if h.Reconnect == nil {
+			t.Skip("no Reconnect hook")
+		}
+
+		client := factory(t)
+		closeOnCleanup(t, h.Timeout, client.Close)
+		h.Reconnect(t)
+
+		eventually(t, h.Timeout, func() error {
+			return client.Ping(context.Background())
+		})
+	})
+}
@@ -1,81 +1,133 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type SQLConfig struct {
-	Enable            bool   `yaml:"enable" default:"false"`
-	Driver            string `yaml:"driver" default:"mysql"`
-	Host              string `yaml:"host" default:"127.0.0.1"`
-	Port              int    `yaml:"port" default:"3306"`
-	Username          string `yaml:"username" default:"root"`
-	Password          string `yaml:"password" default:"root"`
-	Database          string `yaml:"database" default:"mydb"`
-	Options           string `yaml:"options" default:""`
-	Connection        string `yaml:"connection" default:""`
-	AutoReconnect     bool   `yaml:"autoReconnect" default:"false"`
-	StartInterval     int    `yaml:"startInterval" default:"5"`
-	MaxError          int    `yaml:"maxError" default:"5"`
-	Sslmode           string `yaml:"sslmode" default:"false"`
-	TimeoutConnection int    `yaml:"timeoutConnection" default:"3000"`
-	CustomPool        bool   `yaml:"customPool" default:"5"`
-	MaxConn           int    `yaml:"maxConn" default:"5"`
-	MaxIdle           int    `yaml:"maxIdle" default:"5"`
-	LifeTime          int    `yaml:"lifeTime" default:"5"`
+	Enable            bool          `yaml:"enable" default:"false"`
+	Driver            string        `yaml:"driver" default:"mysql"`
+	Host              string        `yaml:"host" default:"127.0.0.1"`
+	Port              int           `yaml:"port" default:"3306"`
+	Username          string        `yaml:"username" default:"root"`
+	Password          string        `yaml:"password" default:"root"`
+	Database          string        `yaml:"database" default:"mydb"`
+	Options           string        `yaml:"options" default:""`
+	Connection        string        `yaml:"connection" default:""`
+	AutoReconnect     bool          `yaml:"autoReconnect" default:"false"`
+	StartInterval     int           `yaml:"startInterval" default:"5"`
+	MaxError          int           `yaml:"maxError" default:"5"`
+	Sslmode           string        `yaml:"sslmode" default:"false"`
+	TimeoutConnection int           `yaml:"timeoutConnection" default:"3000"`
+	CustomPool        bool          `yaml:"customPool" default:"false"`
+	MaxConn           int           `yaml:"maxConn" default:"5"`
+	MaxIdle           int           `yaml:"maxIdle" default:"5"`
+	LifeTime          int           `yaml:"lifeTime" default:"5"`
+	Backoff           BackoffConfig `yaml:"backoff"`
+	TLS               TLSConfig     `yaml:"tls"`
 }
 
 type ElasticSearchProviderConfig struct {
-	Enable        bool   `yaml:"enable" default:"false"`
-	Host          string `yaml:"host" default:"127.0.0.1"`
-	Port          int    `yaml:"port" default:"9200"`
-	Password      string `yaml:"password" default:"root"`
-	Username      string `yaml:"username" default:"root"`
-	IndexName     string `yaml:"indexName" default:"mydb_idx"`
-	MaxError      int    `yaml:"maxError" default:"5"`
-	AutoReconnect bool   `yaml:"autoReconnect" default:"false"`
-	StartInterval int    `yaml:"startInterval" default:"5"`
+	Enable        bool          `yaml:"enable" default:"false"`
+	URL           string        `yaml:"url" default:""`
+	Host          string        `yaml:"host" default:"127.0.0.1"`
+	Port          int           `yaml:"port" default:"9200"`
+	Password      string        `yaml:"password" default:"root"`
+	Username      string        `yaml:"username" default:"root"`
+	IndexName     string        `yaml:"indexName" default:"mydb_idx"`
+	MaxError      int           `yaml:"maxError" default:"5"`
+	AutoReconnect bool          `yaml:"autoReconnect" default:"false"`
+	StartInterval int           `yaml:"startInterval" default:"5"`
+	Backoff       BackoffConfig `yaml:"backoff"`
+	TLS           TLSConfig     `yaml:"tls"`
 }
 
 type RedisProviderConfig struct {
-	Enable        bool   `yaml:"enable" default:"false"`
-	Host          string `yaml:"host" default:"127.0.0.1"`
-	Port          int    `yaml:"port" default:"6379"`
-	Password      string `yaml:"password" default:""`
-	AutoReconnect bool   `yaml:"autoReconnect" default:"false"`
-	StartInterval int    `yaml:"startInterval" default:"5"`
-	MaxError      int    `yaml:"maxError" default:"5"`
+	Enable        bool          `yaml:"enable" default:"false"`
+	URL           string        `yaml:"url" default:""`
+	Host          string        `yaml:"host" default:"127.0.0.1"`
+	Port          int           `yaml:"port" default:"6379"`
+	Password      string        `yaml:"password" default:""`
+	AutoReconnect bool          `yaml:"autoReconnect" default:"false"`
+	StartInterval int           `yaml:"startInterval" default:"5"`
+	MaxError      int           `yaml:"maxError" default:"5"`
+	Backoff       BackoffConfig `yaml:"backoff"`
+	TLS           TLSConfig     `yaml:"tls"`
 }
 
 type MongoProviderConfig struct {
-	Enable            bool   `yaml:"enable" default:"false"`
-	Host              string `yaml:"host" default:"127.0.0.1"`
-	Port              int    `yaml:"port" default:"27017"`
-	Username          string `yaml:"username" default:"root"`
-	Password          string `yaml:"password" default:"root"`
-	AutoReconnect     bool   `yaml:"autoReconnect" default:"false"`
-	MaxError          int    `yaml:"maxError" default:"5"`
-	StartInterval     int    `yaml:"startInterval" default:"5"`
-	TimeoutConnection int    `yaml:"timeoutConnection" default:"3000"`
+	Enable            bool          `yaml:"enable" default:"false"`
+	URL               string        `yaml:"url" default:""`
+	Host              string        `yaml:"host" default:"127.0.0.1"`
+	Port              int           `yaml:"port" default:"27017"`
+	Username          string        `yaml:"username" default:"root"`
+	Password          string        `yaml:"password" default:"root"`
+	AutoReconnect     bool          `yaml:"autoReconnect" default:"false"`
+	MaxError          int           `yaml:"maxError" default:"5"`
+	StartInterval     int           `yaml:"startInterval" default:"5"`
+	TimeoutConnection int           `yaml:"timeoutConnection" default:"3000"`
+	Backoff           BackoffConfig `yaml:"backoff"`
+	TLS               TLSConfig     `yaml:"tls"`
 }
 
 type KafkaProviderConfig struct {
-	Enable           bool   `yaml:"enable" default:"false"`
-	Host             string `yaml:"host" default:"127.0.0.1:9092"`
-	Registry         string `yaml:"registry" default:""`
-	Username         string `yaml:"username" default:""`
-	Password         string `yaml:"password" default:""`
-	SecurityProtocol string `yaml:"securityProtocol" default:"SASL_SSL"`
-	Mechanisms       string `yaml:"mechanisms" default:"PLAIN"`
-	Debug            string `yaml:"debug" default:"consumer"`
+	Enable           bool          `yaml:"enable" default:"false"`
+	Host             string        `yaml:"host" default:"127.0.0.1:9092"`
+	Registry         string        `yaml:"registry" default:""`
+	Username         string        `yaml:"username" default:""`
+	Password         string        `yaml:"password" default:""`
+	SecurityProtocol string        `yaml:"securityProtocol" default:"SASL_SSL"`
+	Mechanisms       string        `yaml:"mechanisms" default:"PLAIN"`
+	Debug            string        `yaml:"debug" default:"consumer"`
+	Backoff          BackoffConfig `yaml:"backoff"`
+	TLS              TLSConfig     `yaml:"tls"`
 }
 
 type RabbitMQProviderConfig struct {
-	Enable              bool   `yaml:"enable" default:"false"`
-	Host                string `yaml:"host" default:"127.0.0.1"`
-	Port                int    `yaml:"port" default:"5672"`
-	Username            string `yaml:"username" default:"guest"`
-	Password            string `yaml:"password" default:"guest"`
-	ReconnectDuration   int    `yaml:"reconnectDuration" default:"5"`
-	DedicatedConnection bool   `yaml:"dedicatedConnection" default:"false"`
+	Enable              bool          `yaml:"enable" default:"false"`
+	URL                 string        `yaml:"url" default:""`
+	Host                string        `yaml:"host" default:"127.0.0.1"`
+	Port                int           `yaml:"port" default:"5672"`
+	Username            string        `yaml:"username" default:"guest"`
+	Password            string        `yaml:"password" default:"guest"`
+	ReconnectDuration   int           `yaml:"reconnectDuration" default:"5"`
+	DedicatedConnection bool          `yaml:"dedicatedConnection" default:"false"`
+	Backoff             BackoffConfig `yaml:"backoff"`
+	TLS                 TLSConfig     `yaml:"tls"`
+}
+
+// BackoffConfig configures the reconnection of a provider, the intervals are in
+// milliseconds. Empty fields fall back to startInterval and maxError of the provider,
+// MaxAttempts counts every attempt where maxError only counts the retries.
+type BackoffConfig struct {
+	InitialInterval int     `yaml:"initialInterval" default:"0"`
+	MaxInterval     int     `yaml:"maxInterval" default:"60000"`
+	Multiplier      float64 `yaml:"multiplier" default:"2"`
+	Jitter          float64 `yaml:"jitter" default:"0.2"`
+	MaxAttempts     int     `yaml:"maxAttempts" default:"0"`
+}
+
+// TLSConfig enables TLS on the connection of a provider, the client certificate is
+// only sent when both CertFile and KeyFile are set. MinVersion is one of 1.0, 1.1,
+// 1.2 and 1.3.
+type TLSConfig struct {
+	Enable             bool   `yaml:"enable" default:"false"`
+	CAFile             string `yaml:"caFile" default:""`
+	CertFile           string `yaml:"certFile" default:""`
+	KeyFile            string `yaml:"keyFile" default:""`
+	ServerName         string `yaml:"serverName" default:""`
+	MinVersion         string `yaml:"minVersion" default:"1.2"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify" default:"false"`
+}
+
+type Config struct {
+	SQL           map[string]SQLConfig                   `yaml:"sql"`
+	Redis         map[string]RedisProviderConfig         `yaml:"redis"`
+	Mongo         map[string]MongoProviderConfig         `yaml:"mongo"`
+	ElasticSearch map[string]ElasticSearchProviderConfig `yaml:"elasticsearch"`
+	RabbitMQ      map[string]RabbitMQProviderConfig      `yaml:"rabbitmq"`
+	Kafka         map[string]KafkaProviderConfig         `yaml:"kafka"`
 }
 
 type RabbitMQOptions struct {
@@ -86,6 +138,29 @@ type RabbitMQOptions struct {
 	AutoDeleted  bool
 	NoWait       bool
 	Encoding     Encoding
+	// Compression of the published bodies of at least CompressionThreshold bytes,
+	// DefaultCompressionThreshold when not positive.
+	Compression          Compression
+	CompressionThreshold int
+	// ManualAck leaves the deliveries of a consumer unsettled until the callback
+	// calls Ack, Nack or Reject, RequeueOnError is the requeue of the Nack of a
+	// Handle handler returning an error.
+	ManualAck      bool
+	RequeueOnError bool
+	// Retry redelivers the messages a Handle handler failed, the deliveries are then
+	// acked manually.
+	Retry *RetryPolicy
+	// Middlewares wrap the callback of the consumer, inside the global ones.
+	Middlewares []ConsumerMiddleware
+	// Workers is the number of callbacks of the consumer running at once, one per
+	// delivery when zero. Sequential runs the callbacks one after the other in the
+	// order of the deliveries. PrefetchCount is the Qos of the channel, Workers when
+	// zero. RateLimit is the number of callbacks started per second, unlimited when
+	// zero.
+	Workers       int
+	Sequential    bool
+	PrefetchCount int
+	RateLimit     int
 }
 
 type KafkaOptions struct {
@@ -97,8 +172,40 @@ type KafkaOptions struct {
 	Encoding       Encoding
 	MultipleThread bool
 	Limiter        int
+	// Compression of the produced bodies of at least CompressionThreshold bytes,
+	// DefaultCompressionThreshold when not positive.
+	Compression          Compression
+	CompressionThreshold int
+	// Retry redelivers the messages a Handle handler failed, the retries go through
+	// the producer of the client.
+	Retry *RetryPolicy
+	// Middlewares wrap the callback of the consumer, inside the global ones.
+	Middlewares []ConsumerMiddleware
+}
+
+// RetryPolicy handles a failed message at most MaxAttempts times, DefaultMaxAttempts
+// when not positive, waiting the Backoff delay, without jitter, before each retry.
+// The message waits in the Retry queue or topic, the destination followed by .retry
+// when empty, then goes to the DeadLetter queue or topic, the destination followed
+// by .dlq when empty, with the headers it was received with and the failure reason.
+// The destination of a RabbitMQ consumer is its queue, a RabbitMQ retry queue is
+// declared per delay with the delay in milliseconds appended.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     BackoffConfig
+	Retry       string
+	DeadLetter  string
 }
 
+const DefaultMaxAttempts = 3
+
+// The headers set on the retried and dead-lettered messages, the values are strings.
+const (
+	HeaderRetryAttempt     = "x-retry-attempt"
+	HeaderRetryNotBefore   = "x-retry-not-before"
+	HeaderDeadLetterReason = "x-dead-letter-reason"
+)
+
 type ElasticSearchOptions struct {
 	Size  int
 	Query string
@@ -121,14 +228,135 @@ const (
 	EncodingJSON
 )
 
+// Compression is the algorithm of the content-encoding header set on the compressed
+// broker messages, the consumers decompress them whatever their options.
+type Compression string
+
+const (
+	CompressionNone   Compression = ""
+	CompressionGzip   Compression = "gzip"
+	CompressionZstd   Compression = "zstd"
+	CompressionSnappy Compression = "snappy"
+)
+
+// HeaderContentEncoding is the message header holding the Compression of the body.
+const HeaderContentEncoding = "content-encoding"
+
+// DefaultCompressionThreshold is the size in bytes from which a body is compressed.
+const DefaultCompressionThreshold = 1024
+
+// HeaderMessageID is the message header holding the ID of the message on the brokers
+// without a message id property, i.e. Kafka.
+const HeaderMessageID = "message-id"
+
+// Messages is a received message. Headers keeps the values as the broker typed them,
+// Body is the raw body once decompressed and Redeliveries is the number of previous
+// deliveries of the message when the broker tells it.
 type Messages interface {
+	ID() string
 	Exchange() string
 	RoutingKey() string
+	Headers() map[string]interface{}
+	Body() []byte
+	Timestamp() time.Time
+	Redeliveries() int
+	Metadata() MessageMetadata
 	Decode(interface{}) error
 	SetContext(context.Context)
 	Context() context.Context
 }
 
+// MessageMetadata holds what the broker of a message tells about it, only the field
+// of that broker is set. Tag is the tag of the client which consumed the message.
+type MessageMetadata struct {
+	Tag   string
+	Kafka *KafkaMetadata
+	AMQP  *AMQPMetadata
+}
+
+type KafkaMetadata struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	// Value is the decompressed value of the message, before the schema registry
+	// decoding.
+	Value []byte
+}
+
+type AMQPMetadata struct {
+	DeliveryTag uint64
+	Exchange    string
+	RoutingKey  string
+	Redelivered bool
+}
+
+// PayloadLogging sets how much of a message or query body the library writes in its
+// logs.
+type PayloadLogging int
+
+const (
+	// PayloadHash logs the size and a short SHA-256 of the body.
+	PayloadHash PayloadLogging = iota
+	// PayloadOmit logs the size of the body only.
+	PayloadOmit
+	// PayloadTruncate logs the beginning of the body, up to the payload limit.
+	PayloadTruncate
+	// PayloadFull logs the whole body.
+	PayloadFull
+)
+
+type ClientInfo struct {
+	Provider string `json:"provider"`
+	Tag      string `json:"tag"`
+}
+
+type HealthState string
+
+const (
+	HealthUp   HealthState = "up"
+	HealthDown HealthState = "down"
+)
+
+type HealthStatus struct {
+	Provider  string        `json:"provider"`
+	Tag       string        `json:"tag"`
+	Status    HealthState   `json:"status"`
+	Latency   time.Duration `json:"latency"`
+	Error     string        `json:"error,omitempty"`
+	LastError string        `json:"lastError,omitempty"`
+	CheckedAt time.Time     `json:"checkedAt"`
+}
+
+type HealthReport struct {
+	Status  HealthState    `json:"status"`
+	Clients []HealthStatus `json:"clients"`
+}
+
+type EventType string
+
+const (
+	EventConnected          EventType = "connected"
+	EventDisconnected       EventType = "disconnected"
+	EventReconnecting       EventType = "reconnecting"
+	EventGaveUp             EventType = "gave_up"
+	EventConsumerSubscribed EventType = "consumer_subscribed"
+)
+
+// Event reports a change of the connection of a client. Attempt and Delay are set
+// on Reconnecting and GaveUp, Destination, the exchange or the topic, on
+// ConsumerSubscribed.
+type Event struct {
+	Type        EventType     `json:"type"`
+	Provider    string        `json:"provider"`
+	Tag         string        `json:"tag"`
+	Destination string        `json:"destination,omitempty"`
+	Attempt     int           `json:"attempt,omitempty"`
+	Delay       time.Duration `json:"delay,omitempty"`
+	Error       error         `json:"-"`
+	Time        time.Time     `json:"time"`
+}
+
 type EmbeddedOptions struct {
 	Directory string
 }
@@ -0,0 +1,211 @@
+package interfaces
+
+import (
+	"context"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FromElasticSearch returns the v2 interface of the client. A client without a V2
+// method is wrapped, the context is then only checked before each call.
+func FromElasticSearch(client database.ElasticSearch) ElasticSearch {
+	if val, ok := client.(interface{ V2() ElasticSearch }); ok {
+		return val.V2()
+	}
+	return elasticSearch{client}
+}
+
+func FromRedis(client database.Redis) Redis {
+	if val, ok := client.(interface{ V2() Redis }); ok {
+		return val.V2()
+	}
+	return redisClient{client}
+}
+
+func FromSQL(client database.SQL) SQL {
+	if val, ok := client.(interface{ V2() SQL }); ok {
+		return val.V2()
+	}
+	return sqlClient{client}
+}
+
+func FromMongo(client database.Mongo) Mongo {
+	if val, ok := client.(interface{ V2() Mongo }); ok {
+		return val.V2()
+	}
+	return mongoClient{client}
+}
+
+// FromKafka returns the v2 interface of the client. A client without a V2 method is
+// wrapped and can not report the errors of Consumer and Producer.
+func FromKafka(client database.Kafka) Kafka {
+	if val, ok := client.(interface{ V2() Kafka }); ok {
+		return val.V2()
+	}
+	return kafkaClient{client}
+}
+
+// FromRabbitMQ returns the v2 interface of the client. A client without a V2 method
+// is wrapped and can not report the errors of Consumer and Producer.
+func FromRabbitMQ(client database.RabbitMQ) RabbitMQ {
+	if val, ok := client.(interface{ V2() RabbitMQ }); ok {
+		return val.V2()
+	}
+	return rabbitMQClient{client}
+}
+
+type elasticSearch struct {
+	database.ElasticSearch
+}
+
+func (c elasticSearch) Connect(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.ElasticSearch.ElasticSearch()
+}
+
+func (c elasticSearch) Search(ctx context.Context, config database.ElasticSearchOptions) (*database.SearchResultsElasticSearch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ElasticSearch.Search(config)
+}
+
+func (c elasticSearch) CreateIndex(ctx context.Context, name string, mapping string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.ElasticSearch.CreateIndex(name, mapping)
+}
+
+func (c elasticSearch) Create(ctx context.Context, index string, id string, values interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.ElasticSearch.Create(index, id, values)
+}
+
+func (c elasticSearch) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.ElasticSearch.Delete(id)
+}
+
+type redisClient struct {
+	database.Redis
+}
+
+func (c redisClient) Connect(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Redis.Init()
+}
+
+type sqlClient struct {
+	database.SQL
+}
+
+func (c sqlClient) Connect(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.SQL.LoadSQL()
+}
+
+type mongoClient struct {
+	database.Mongo
+}
+
+func (c mongoClient) Connect(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Mongo.Init()
+}
+
+func (c mongoClient) Find(ctx context.Context, db, table string, filter bson.M, opt ...*options.FindOptions) ([]bson.M, error) {
+	return Find(ctx, c.Mongo, db, table, filter, opt...)
+}
+
+// Find runs the query with the driver database of the client and decodes every
+// document of the cursor.
+func Find(ctx context.Context, client database.Mongo, db, table string, filter bson.M, opt ...*options.FindOptions) ([]bson.M, error) {
+	mdb := client.SetDatabase(db)
+	if mdb == nil {
+		return nil, dberrors.New("mongo", "", "find", dberrors.ErrNotConnected)
+	}
+
+	cur, err := mdb.Collection(table).Find(ctx, filter, opt...)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	results := make([]bson.M, 0)
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+type kafkaClient struct {
+	database.Kafka
+}
+
+func (c kafkaClient) Consumer(ctx context.Context, options database.KafkaOptions, callback database.ConsumerCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.Kafka.Consumer(options, callback)
+	return nil
+}
+
+func (c kafkaClient) Handle(ctx context.Context, options database.KafkaOptions, handler database.ConsumerHandler) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.Kafka.Handle(options, handler)
+	return nil
+}
+
+func (c kafkaClient) Producer(ctx context.Context, isReady database.ProducerIsReady) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.Kafka.Producer(isReady)
+	return nil
+}
+
+type rabbitMQClient struct {
+	database.RabbitMQ
+}
+
+func (c rabbitMQClient) Consumer(ctx context.Context, options database.RabbitMQOptions, callback database.ConsumerCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.RabbitMQ.Consumer(options, callback)
+	return nil
+}
+
+func (c rabbitMQClient) Handle(ctx context.Context, options database.RabbitMQOptions, handler database.ConsumerHandler) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.RabbitMQ.Handle(options, handler)
+	return nil
+}
+
+func (c rabbitMQClient) Producer(ctx context.Context, options database.RabbitMQOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.RabbitMQ.Producer(options)
+	return nil
+}
@@ -0,0 +1,76 @@
+// Package interfaces is the second version of the client interfaces: every I/O
+// method takes a context and every failure, a disabled provider or missing options
+// included, is returned as an error. The clients of the library expose it with V2,
+// the From functions convert any v1 client during the migration.
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+)
+
+type ElasticSearch interface {
+	Elastic() *elasticsearch.Client
+	Connect(ctx context.Context) error
+	Search(ctx context.Context, config database.ElasticSearchOptions) (*database.SearchResultsElasticSearch, error)
+	CreateIndex(ctx context.Context, name string, mapping string) error
+	Create(ctx context.Context, index string, id string, values interface{}) error
+	Delete(ctx context.Context, id string) error
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+type Redis interface {
+	Connect(ctx context.Context) error
+	GetPool() *redis.PoolStats
+	Set(ctx context.Context, key string, val interface{}, ttl time.Duration) error
+	Get(ctx context.Context, key string) (string, error)
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+type SQL interface {
+	Orm() *gorm.DB
+	Connect(ctx context.Context) error
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+type Mongo interface {
+	Connect(ctx context.Context) error
+	// SetDatabase returns nil when the client is not connected.
+	SetDatabase(db string) *mongo.Database
+	Find(ctx context.Context, db, table string, filter bson.M, opt ...*options.FindOptions) ([]bson.M, error)
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// Kafka starts the consumers and the producer in the background, the context only
+// bounds their setup, they run until Close.
+type Kafka interface {
+	Consumer(ctx context.Context, options database.KafkaOptions, callback database.ConsumerCallback) error
+	Handle(ctx context.Context, options database.KafkaOptions, handler database.ConsumerHandler) error
+	Producer(ctx context.Context, isReady database.ProducerIsReady) error
+	Push(ctx context.Context, id string, options database.KafkaOptions, body interface{}, cb database.ConsumerCallback) error
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// RabbitMQ starts the consumers and the producer in the background, the context
+// only bounds their setup, they run until Close.
+type RabbitMQ interface {
+	Consumer(ctx context.Context, options database.RabbitMQOptions, callback database.ConsumerCallback) error
+	Handle(ctx context.Context, options database.RabbitMQOptions, handler database.ConsumerHandler) error
+	Producer(ctx context.Context, options database.RabbitMQOptions) error
+	Push(ctx context.Context, id, key string, body interface{}, cb database.ConsumerCallback) error
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
@@ -0,0 +1,156 @@
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+const (
+	DefaultInitialInterval = 1 * time.Second
+	DefaultMaxInterval     = 60 * time.Second
+	DefaultMultiplier      = 2.0
+	DefaultJitter          = 0.2
+)
+
+// Policy is an exponential backoff with jitter. The delay after the attempt n is
+// InitialInterval * Multiplier^(n-1), capped at MaxInterval, then randomized by
+// +/- Jitter, a negative Jitter disables it. A MaxAttempts of zero retries until the
+// context is done.
+type Policy struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	Jitter          float64
+	MaxAttempts     int
+}
+
+// Notify is called before waiting for the next attempt.
+type Notify func(attempt int, delay time.Duration, err error)
+
+var (
+	random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	mutex  sync.Mutex
+)
+
+// FromConfig builds the policy of a provider. The fields left empty fall back to
+// the legacy startInterval (seconds) and maxError of the provider, then to the
+// package defaults. maxError keeps its legacy meaning, the number of retries after
+// the first attempt, while MaxAttempts counts every attempt. A negative maxError
+// retries until the context is done.
+func FromConfig(cfg database.BackoffConfig, startInterval, maxError int) Policy {
+	policy := Policy{
+		InitialInterval: time.Duration(cfg.InitialInterval) * time.Millisecond,
+		MaxInterval:     time.Duration(cfg.MaxInterval) * time.Millisecond,
+		Multiplier:      cfg.Multiplier,
+		Jitter:          cfg.Jitter,
+		MaxAttempts:     cfg.MaxAttempts,
+	}
+
+	if policy.InitialInterval <= 0 {
+		policy.InitialInterval = time.Duration(startInterval) * time.Second
+	}
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = maxError
+		if maxError >= 0 {
+			policy.MaxAttempts = maxError + 1
+		}
+	}
+
+	return policy.withDefaults()
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.InitialInterval <= 0 {
+		p.InitialInterval = DefaultInitialInterval
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = DefaultMaxInterval
+	}
+	if p.MaxInterval < p.InitialInterval {
+		p.MaxInterval = p.InitialInterval
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultMultiplier
+	}
+	if p.Jitter == 0 {
+		p.Jitter = DefaultJitter
+	} else if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	return p
+}
+
+// Delay returns the time to wait after the failed attempt, the first attempt is 1.
+func (p Policy) Delay(attempt int) time.Duration {
+	p = p.withDefaults()
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt-1))
+	if delay > float64(p.MaxInterval) {
+		delay = float64(p.MaxInterval)
+	}
+
+	if p.Jitter > 0 {
+		mutex.Lock()
+		delta := (random.Float64()*2 - 1) * p.Jitter * delay
+		mutex.Unlock()
+		delay += delta
+	}
+
+	return time.Duration(delay)
+}
+
+// Exhausted reports whether no attempt is left after the given number of attempts.
+func (p Policy) Exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt >= p.MaxAttempts
+}
+
+// Wait blocks for the delay after the attempt or until the context is done.
+func (p Policy) Wait(ctx context.Context, attempt int) error {
+	return Sleep(ctx, p.Delay(attempt))
+}
+
+// Sleep blocks for the delay or until the context is done.
+func Sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Retry calls op until it succeeds, the attempts are exhausted or the context is
+// done. The last error of op is returned.
+func (p Policy) Retry(ctx context.Context, op func(ctx context.Context) error, notify Notify) error {
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if p.Exhausted(attempt) {
+			return err
+		}
+
+		delay := p.Delay(attempt)
+		if notify != nil {
+			notify(attempt, delay, err)
+		}
+
+		if e := Sleep(ctx, delay); e != nil {
+			return fmt.Errorf("%w: %v", e, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/events"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+func TestFromConfigMaxError(t *testing.T) {
+	tests := []struct {
+		cfg      database.BackoffConfig
+		maxError int
+		want     int
+	}{
+		{database.BackoffConfig{}, 5, 6},
+		{database.BackoffConfig{}, 0, 1},
+		{database.BackoffConfig{}, -1, -1},
+		{database.BackoffConfig{MaxAttempts: 3}, 5, 3},
+	}
+
+	for _, tt := range tests {
+		if got := FromConfig(tt.cfg, 1, tt.maxError).MaxAttempts; got != tt.want {
+			t.Errorf("FromConfig(%+v, maxError %d).MaxAttempts = %d, want %d", tt.cfg, tt.maxError, got, tt.want)
+		}
+	}
+}
+
+func TestDelayNegativeJitter(t *testing.T) {
+	policy := FromConfig(database.BackoffConfig{InitialInterval: 1000, Jitter: -1}, 0, -1)
+	for i := 0; i < 10; i++ {
+		if got := policy.Delay(1); got != time.Second {
+			t.Fatalf("Delay(1) = %s, want 1s", got)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	received := make(chan database.EventType, 10)
+	events.Subscribe(func(e database.Event) {
+		if e.Provider == "watch-test" {
+			received <- e.Type
+		}
+	})
+
+	// the connection is lost on the second check, the first reconnection fails and
+	// the second one dials a working client
+	var checks, reconnects int32
+	ping := func(ctx context.Context) error {
+		if atomic.AddInt32(&checks, 1) == 2 {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+	reconnect := func(ctx context.Context) error {
+		if atomic.AddInt32(&reconnects, 1) == 1 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	lg := log.NewLib()
+	lg.Init("backoff test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	policy := Policy{InitialInterval: time.Millisecond, Jitter: -1, MaxAttempts: 5}
+	go policy.Watch(ctx, "watch-test", "default", lg, time.Millisecond, ping, reconnect)
+
+	for _, want := range []database.EventType{database.EventDisconnected, database.EventReconnecting, database.EventConnected} {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("event = %s, want %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event", want)
+		}
+	}
+
+	if got := atomic.LoadInt32(&reconnects); got != 2 {
+		t.Errorf("reconnects = %d, want 2", got)
+	}
+}
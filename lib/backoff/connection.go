@@ -0,0 +1,84 @@
+package backoff
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/events"
+	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
+)
+
+// Connection connects the client of a data store provider with its policy and, with
+// AutoReconnect, watches it until Stop. Connect dials a new client and swaps it with
+// the current one, Ping checks the current one.
+type Connection struct {
+	Provider      string
+	Tag           string
+	Logger        logger.Logger
+	Policy        Policy
+	AutoReconnect bool
+	Connect       func(ctx context.Context) error
+	Ping          func(ctx context.Context) error
+
+	unwatch context.CancelFunc
+	mutex   sync.Mutex
+}
+
+// NewConnection returns the connection of a provider, its policy is the one of
+// FromConfig with a single attempt without autoReconnect.
+func NewConnection(provider, tag string, lg logger.Logger, cfg database.BackoffConfig, startInterval, maxError int, autoReconnect bool) *Connection {
+	policy := FromConfig(cfg, startInterval, maxError)
+	if !autoReconnect {
+		policy.MaxAttempts = 1
+	}
+	return &Connection{Provider: provider, Tag: tag, Logger: lg, Policy: policy, AutoReconnect: autoReconnect}
+}
+
+// Start connects until the attempts are exhausted or the context is done, then
+// watches the connection with AutoReconnect.
+func (c *Connection) Start(ctx context.Context) error {
+	attempts := 1
+	err := c.Policy.Retry(ctx, c.Connect, func(attempt int, delay time.Duration, err error) {
+		attempts = attempt + 1
+		c.Logger.Error(err)
+		c.Logger.Warning("[%s] Reconnecting in %s", c.Tag, delay)
+		events.Reconnecting(c.Provider, c.Tag, attempt, delay, err)
+	})
+	if err != nil {
+		events.GaveUp(c.Provider, c.Tag, attempts, err)
+		return dberrors.New(c.Provider, c.Tag, "connect", err)
+	}
+
+	events.Connected(c.Provider, c.Tag)
+	if !c.AutoReconnect {
+		return nil
+	}
+
+	watch, cancel := context.WithCancel(context.Background())
+	c.mutex.Lock()
+	if c.unwatch != nil {
+		c.unwatch()
+	}
+	c.unwatch = cancel
+	c.mutex.Unlock()
+
+	go c.Policy.Watch(watch, c.Provider, c.Tag, c.Logger, 0, c.Ping, c.Connect)
+	return nil
+}
+
+// Stop stops watching the connection, a nil connection is never watched.
+func (c *Connection) Stop() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.unwatch != nil {
+		c.unwatch()
+		c.unwatch = nil
+	}
+}
@@ -0,0 +1,57 @@
+package backoff
+
+import (
+	"context"
+	"time"
+
+	"github.com/fajarardiyanto/flt-go-database/lib/events"
+	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
+)
+
+// DefaultWatchInterval is the time between two checks of Watch.
+const DefaultWatchInterval = 10 * time.Second
+
+// Watch checks the connection of a provider with ping every interval, or
+// DefaultWatchInterval when not positive, until the context is done. Once a ping
+// fails the connection is reported lost and reconnect, which dials a new client, is
+// retried with the policy until the connection is back. The watch stops when the
+// attempts are exhausted.
+func (p Policy) Watch(ctx context.Context, provider, tag string, lg logger.Logger, interval time.Duration, ping, reconnect func(ctx context.Context) error) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	for {
+		if err := Sleep(ctx, interval); err != nil {
+			return
+		}
+
+		check, cancel := context.WithTimeout(ctx, interval)
+		err := ping(check)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+
+		lg.Error("[%s] connection lost: %s", tag, err)
+		events.Disconnected(provider, tag, err)
+
+		attempts := 1
+		err = p.Retry(ctx, reconnect, func(attempt int, delay time.Duration, err error) {
+			attempts = attempt + 1
+			lg.Warning("[%s] Reconnecting in %s", tag, delay)
+			events.Reconnecting(provider, tag, attempt, delay, err)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			lg.Error("[%s] giving up reconnecting after %d attempts", tag, attempts)
+			events.GaveUp(provider, tag, attempts, err)
+			return
+		}
+
+		lg.Info("[%s] connection restored", tag)
+		events.Connected(provider, tag)
+	}
+}
@@ -0,0 +1,11 @@
+package lib
+
+import (
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+// RegisterCodec adds or replaces the codec of the encoding used by the producers and
+// consumers of the process, see database.RegisterCodec.
+func RegisterCodec(enc database.Encoding, codec database.Codec) {
+	database.RegisterCodec(enc, codec)
+}
@@ -0,0 +1,120 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// MaxDecompressedSize is the size in bytes of the largest body Decompress returns.
+const MaxDecompressedSize = 64 << 20
+
+// ErrTooLarge is returned by Decompress for a body larger than MaxDecompressedSize
+// once decompressed.
+var ErrTooLarge = fmt.Errorf("decompressed body larger than %d bytes", MaxDecompressedSize)
+
+var (
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdOnce    sync.Once
+)
+
+// the zstd encoder and decoder are safe for concurrent use with EncodeAll and
+// DecodeAll, they are created once on first use.
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxDecompressedSize))
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+// Compress returns the data compressed with the algorithm when it holds at least
+// threshold bytes, DefaultCompressionThreshold when not positive. The content encoding
+// is empty when the data is returned as it is.
+func Compress(algorithm database.Compression, threshold int, data []byte) ([]byte, string, error) {
+	if threshold <= 0 {
+		threshold = database.DefaultCompressionThreshold
+	}
+	if algorithm == database.CompressionNone || len(data) < threshold {
+		return data, "", nil
+	}
+
+	switch algorithm {
+	case database.CompressionGzip:
+		buffer := bytes.NewBuffer(nil)
+		w := gzip.NewWriter(buffer)
+		if _, err := w.Write(data); err != nil {
+			return nil, "", err
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), string(algorithm), nil
+	case database.CompressionZstd:
+		encoder, _ := zstdCodec()
+		return encoder.EncodeAll(data, nil), string(algorithm), nil
+	case database.CompressionSnappy:
+		return snappy.Encode(nil, data), string(algorithm), nil
+	}
+	return nil, "", fmt.Errorf("compression %q not supported", algorithm)
+}
+
+// Decompress returns the data of a message with the content-encoding header, the
+// data is returned as it is when the content encoding is empty. ErrTooLarge is
+// returned once the data exceeds MaxDecompressedSize.
+func Decompress(contentEncoding string, data []byte) ([]byte, error) {
+	switch database.Compression(contentEncoding) {
+	case database.CompressionNone:
+		return data, nil
+	case database.CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		data, err := io.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > MaxDecompressedSize {
+			return nil, ErrTooLarge
+		}
+		return data, nil
+	case database.CompressionZstd:
+		_, decoder := zstdCodec()
+		data, err := decoder.DecodeAll(data, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+			return nil, ErrTooLarge
+		}
+		return data, err
+	case database.CompressionSnappy:
+		size, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if size > MaxDecompressedSize {
+			return nil, ErrTooLarge
+		}
+		return snappy.Decode(nil, data)
+	}
+	return nil, fmt.Errorf("content encoding %q not supported", contentEncoding)
+}
+
+// Header returns the content encoding of the message headers.
+func Header(headers map[string]interface{}) string {
+	switch val := headers[database.HeaderContentEncoding].(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package compress
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+func TestDecompressMaxSize(t *testing.T) {
+	for _, algorithm := range []database.Compression{
+		database.CompressionGzip,
+		database.CompressionZstd,
+		database.CompressionSnappy,
+	} {
+		for _, size := range []int{MaxDecompressedSize, MaxDecompressedSize + 1} {
+			data, contentEncoding, err := Compress(algorithm, 0, make([]byte, size))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Decompress(contentEncoding, data)
+			switch {
+			case size > MaxDecompressedSize && !errors.Is(err, ErrTooLarge):
+				t.Errorf("%s: Decompress of %d bytes = %v, want %v", algorithm, size, err, ErrTooLarge)
+			case size <= MaxDecompressedSize && err != nil:
+				t.Errorf("%s: Decompress of %d bytes = %v", algorithm, size, err)
+			case size <= MaxDecompressedSize && !bytes.Equal(got, make([]byte, size)):
+				t.Errorf("%s: Decompress of %d bytes returned %d bytes", algorithm, size, len(got))
+			}
+		}
+	}
+}
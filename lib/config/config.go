@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding values of the
+// configuration document, e.g. DATABASE_SQL_PRIMARY_PASSWORD.
+const EnvPrefix = "DATABASE"
+
+type document struct {
+	SQL           map[string]yaml.Node `yaml:"sql"`
+	Redis         map[string]yaml.Node `yaml:"redis"`
+	Mongo         map[string]yaml.Node `yaml:"mongo"`
+	ElasticSearch map[string]yaml.Node `yaml:"elasticsearch"`
+	RabbitMQ      map[string]yaml.Node `yaml:"rabbitmq"`
+	Kafka         map[string]yaml.Node `yaml:"kafka"`
+}
+
+func LoadFile(path string) (*database.Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Load(file)
+}
+
+func Load(r io.Reader) (*database.Config, error) {
+	var doc document
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+
+	config := &database.Config{
+		SQL:           map[string]database.SQLConfig{},
+		Redis:         map[string]database.RedisProviderConfig{},
+		Mongo:         map[string]database.MongoProviderConfig{},
+		ElasticSearch: map[string]database.ElasticSearchProviderConfig{},
+		RabbitMQ:      map[string]database.RabbitMQProviderConfig{},
+		Kafka:         map[string]database.KafkaProviderConfig{},
+	}
+
+	for tag, node := range doc.SQL {
+		var cfg database.SQLConfig
+		if err := decode("sql", tag, node, &cfg); err != nil {
+			return nil, err
+		}
+		config.SQL[tag] = cfg
+	}
+
+	for tag, node := range doc.Redis {
+		var cfg database.RedisProviderConfig
+		if err := decode("redis", tag, node, &cfg); err != nil {
+			return nil, err
+		}
+		config.Redis[tag] = cfg
+	}
+
+	for tag, node := range doc.Mongo {
+		var cfg database.MongoProviderConfig
+		if err := decode("mongo", tag, node, &cfg); err != nil {
+			return nil, err
+		}
+		config.Mongo[tag] = cfg
+	}
+
+	for tag, node := range doc.ElasticSearch {
+		var cfg database.ElasticSearchProviderConfig
+		if err := decode("elasticsearch", tag, node, &cfg); err != nil {
+			return nil, err
+		}
+		config.ElasticSearch[tag] = cfg
+	}
+
+	for tag, node := range doc.RabbitMQ {
+		var cfg database.RabbitMQProviderConfig
+		if err := decode("rabbitmq", tag, node, &cfg); err != nil {
+			return nil, err
+		}
+		config.RabbitMQ[tag] = cfg
+	}
+
+	for tag, node := range doc.Kafka {
+		var cfg database.KafkaProviderConfig
+		if err := decode("kafka", tag, node, &cfg); err != nil {
+			return nil, err
+		}
+		config.Kafka[tag] = cfg
+	}
+
+	return config, nil
+}
+
+func decode(section, tag string, node yaml.Node, out interface{}) error {
+	if err := SetDefaults(out); err != nil {
+		return fmt.Errorf("%s %q: %w", section, tag, err)
+	}
+
+	if err := node.Decode(out); err != nil {
+		return fmt.Errorf("%s %q: %w", section, tag, err)
+	}
+
+	if err := SetFromEnv(out, envName(EnvPrefix, section, tag)); err != nil {
+		return fmt.Errorf("%s %q: %w", section, tag, err)
+	}
+
+	return nil
+}
+
+// SetDefaults fills every field of the struct pointed by out, nested structs
+// included, with the value of its `default` tag.
+func SetDefaults(out interface{}) error {
+	return walk(out, func(field reflect.StructField, value reflect.Value) error {
+		if value.Kind() == reflect.Struct {
+			return SetDefaults(value.Addr().Interface())
+		}
+
+		def, ok := field.Tag.Lookup("default")
+		if !ok || len(def) == 0 {
+			return nil
+		}
+		return setValue(field, value, def)
+	})
+}
+
+// SetFromEnv overrides every field of the struct pointed by out with the environment
+// variable named prefix_YAMLKEY, when it is set. The fields of a nested struct are
+// named prefix_YAMLKEY_NESTEDKEY.
+func SetFromEnv(out interface{}, prefix string) error {
+	return walk(out, func(field reflect.StructField, value reflect.Value) error {
+		key := field.Tag.Get("yaml")
+		if idx := strings.Index(key, ","); idx >= 0 {
+			key = key[:idx]
+		}
+		if len(key) == 0 || key == "-" {
+			return nil
+		}
+
+		if value.Kind() == reflect.Struct {
+			return SetFromEnv(value.Addr().Interface(), envName(prefix, key))
+		}
+
+		env, ok := os.LookupEnv(envName(prefix, key))
+		if !ok {
+			return nil
+		}
+		return setValue(field, value, env)
+	})
+}
+
+func walk(out interface{}, fn func(reflect.StructField, reflect.Value) error) error {
+	ref := reflect.ValueOf(out)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected pointer to struct, got %T", out)
+	}
+
+	ref = ref.Elem()
+	for i := 0; i < ref.NumField(); i++ {
+		field := ref.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if err := fn(field, ref.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setValue(field reflect.StructField, value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		val, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("field %s: invalid bool %q", field.Name, raw)
+		}
+		value.SetBool(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("field %s: invalid integer %q", field.Name, raw)
+		}
+		value.SetInt(val)
+	case reflect.Float32, reflect.Float64:
+		val, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("field %s: invalid float %q", field.Name, raw)
+		}
+		value.SetFloat(val)
+	default:
+		return fmt.Errorf("field %s: unsupported kind %s", field.Name, value.Kind())
+	}
+
+	return nil
+}
+
+func envName(parts ...string) string {
+	name := strings.Join(parts, "_")
+	name = strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	return strings.ToUpper(name)
+}
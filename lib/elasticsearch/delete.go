@@ -1,12 +1,20 @@
 package elasticsearch
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 )
 
 func (c *ElasticSearch) Delete(id string) error {
-	res, err := c.elastic.Delete(c.config.IndexName, id)
+	return c.delete(context.Background(), id)
+}
+
+func (c *ElasticSearch) delete(ctx context.Context, id string) error {
+	elastic, err := c.client("delete")
+	if err != nil {
+		return err
+	}
+
+	res, err := elastic.Delete(c.config.IndexName, id, elastic.Delete.WithContext(ctx))
 	if err != nil {
 		c.log.Error(err)
 		return err
@@ -14,15 +22,9 @@ func (c *ElasticSearch) Delete(id string) error {
 	defer res.Body.Close()
 
 	if res.IsError() {
-		var e map[string]interface{}
-		if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
-			c.log.Error(err)
-			return err
-		}
-
-		er := fmt.Sprintf("[%s] %s: %s", res.Status(), e["error"].(map[string]interface{})["type"], e["error"].(map[string]interface{})["reason"])
-		c.log.Error(er)
-		return fmt.Errorf(er)
+		err := c.responseError("delete", res)
+		c.log.Error(err)
+		return err
 	}
 
 	return nil
@@ -1,102 +1,189 @@
 package elasticsearch
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/backoff"
+	"github.com/fajarardiyanto/flt-go-database/lib/redact"
+	"github.com/fajarardiyanto/flt-go-database/lib/secret"
+	"github.com/fajarardiyanto/flt-go-database/lib/tlsutil"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	"github.com/fajarardiyanto/flt-go-utils/hash"
 )
 
 type ElasticSearch struct {
-	tag         string
-	id          string
-	reconnectAt int
-	lastTimeout time.Duration
-	config      database.ElasticSearchProviderConfig
-	elastic     *elasticsearch.Client
-	log         logger.Logger
+	tag     string
+	id      string
+	config  database.ElasticSearchProviderConfig
+	elastic *elasticsearch.Client
+	log     logger.Logger
+	conn    *backoff.Connection
 	sync.RWMutex
 }
 
 func (c *ElasticSearch) Elastic() *elasticsearch.Client {
+	c.RLock()
+	defer c.RUnlock()
 	return c.elastic
 }
 
 func NewElasticSearch(tag string, lo logger.Logger, config database.ElasticSearchProviderConfig) database.ElasticSearch {
-	id := hash.CreateSmallHash(10, config.Host,
+	id := hash.CreateSmallHash(10, config.URL, config.Host,
 		strconv.Itoa(config.Port),
 		config.Username,
 		config.Password)
 
-	es := ElasticSearch{tag: strings.ToLower(tag), log: lo, config: config, id: id}
+	es := &ElasticSearch{tag: strings.ToLower(tag), log: lo, config: config, id: id}
+	es.conn = backoff.NewConnection("elasticsearch", es.tag, lo, config.Backoff, config.StartInterval, config.MaxError, config.AutoReconnect)
+	es.conn.Connect, es.conn.Ping = es.connect, es.Ping
 
 	lo.Debug("ElasticSearch Client %s:%d has been registered", config.Host, config.Port)
 
-	return &es
+	return es
 }
 
-func (c *ElasticSearch) OnElasticSearchError(e error) (err error) {
-	if c.config.MaxError == 0 {
-		c.config.MaxError = 5
+func (c *ElasticSearch) Ping(ctx context.Context) error {
+	elastic := c.Elastic()
+	if elastic == nil {
+		return dberrors.New("elasticsearch", c.tag, "ping", dberrors.ErrNotConnected)
 	}
 
-	if c.reconnectAt >= c.config.MaxError {
-		return e
-	}
-
-	c.reconnectAt++
-	if !c.config.AutoReconnect {
-		return e
+	res, err := elastic.Info(elastic.Info.WithContext(ctx))
+	if err != nil {
+		return err
 	}
+	defer res.Body.Close()
 
-	var ttm = 2
-	if c.lastTimeout.Seconds() == 0 {
-		ttm = 1
-		c.lastTimeout = time.Duration(c.config.StartInterval) * time.Second
+	if res.IsError() {
+		return c.responseError("ping", res)
 	}
 
-	c.lastTimeout = time.Duration(int(c.lastTimeout.Seconds())*ttm) * time.Second
+	return nil
+}
 
-	c.log.Error(err)
-	c.log.Warning("[%s] Reconnecting in %s", c.id, c.lastTimeout)
-	time.Sleep(c.lastTimeout)
+// Close stops the background reconnection, the client holds no connection besides
+// the idle HTTP ones.
+func (c *ElasticSearch) Close(ctx context.Context) error {
+	c.conn.Stop()
+	return nil
+}
 
-	return c.ElasticSearch()
+func (c *ElasticSearch) ElasticSearch() error {
+	return c.start(context.Background())
 }
 
-func (c *ElasticSearch) ElasticSearch() (err error) {
+// start connects the client, then watches it with AutoReconnect.
+func (c *ElasticSearch) start(ctx context.Context) (err error) {
 	if !c.config.Enable {
 		msg := "aborted, elasticsearch not enable in config, double check configuration again"
 		c.log.Error(msg)
-		return fmt.Errorf(msg)
+		return dberrors.New("elasticsearch", c.tag, "connect", dberrors.ErrDisabled)
+	}
+
+	if _, err := c.addresses(ctx); err != nil {
+		c.log.Error(err)
+		return err
+	}
+
+	return c.conn.Start(ctx)
+}
+
+// addresses returns the node URLs of the config when set, otherwise the Host,
+// including its scheme, and Port.
+func (c *ElasticSearch) addresses(ctx context.Context) ([]string, error) {
+	if len(c.config.URL) == 0 {
+		return []string{fmt.Sprintf("%s:%d", c.config.Host, c.config.Port)}, nil
+	}
+
+	nodes, err := secret.Resolve(ctx, c.config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	for _, addr := range strings.Split(nodes, ",") {
+		addr = strings.TrimSpace(addr)
+		if len(addr) == 0 {
+			continue
+		}
+
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid elasticsearch url %q: %v", redact.URL(addr), errors.Unwrap(err))
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid elasticsearch url %q: scheme must be http or https", u.Redacted())
+		}
+		if len(u.Host) == 0 {
+			return nil, fmt.Errorf("invalid elasticsearch url %q: missing host", u.Redacted())
+		}
+		addresses = append(addresses, addr)
+	}
+
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("invalid elasticsearch url: no node address")
+	}
+	return addresses, nil
+}
+
+func (c *ElasticSearch) connect(ctx context.Context) (err error) {
+	addresses, err := c.addresses(ctx)
+	if err != nil {
+		return err
+	}
+
+	var base http.RoundTripper = http.DefaultTransport
+	if c.config.TLS.Enable {
+		tlsConfig, err := tlsutil.Config(c.config.TLS)
+		if err != nil {
+			return err
+		}
+
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = tlsConfig
+		base = tr
+	}
+
+	username, password := c.config.Username, c.config.Password
+	if err := secret.ResolveAll(ctx, &username, &password); err != nil {
+		return err
 	}
 
-	url := fmt.Sprintf("%s:%d", c.config.Host, c.config.Port)
+	// the credentials of a node URL take precedence
 	cfg := elasticsearch.Config{
-		Addresses: []string{url},
+		Addresses: addresses,
+		Username:  username,
+		Password:  password,
+		Transport: &transport{tag: c.tag, base: base},
 	}
 
 	conn, err := elasticsearch.NewClient(cfg)
 	if err != nil {
-		return c.OnElasticSearchError(fmt.Errorf("[%s] %s", c.id, err.Error()))
+		return fmt.Errorf("[%s] %s", c.id, err.Error())
 	}
 
-	es, errEs := conn.Info()
+	es, errEs := conn.Info(conn.Info.WithContext(ctx))
 	if errEs != nil {
-		return c.OnElasticSearchError(fmt.Errorf("[%s] %s", c.id, errEs.Error()))
+		return fmt.Errorf("[%s] %s", c.id, errEs.Error())
 	}
 	defer func() {
 		err = es.Body.Close()
 		return
 	}()
 
+	c.Lock()
 	c.elastic = conn
+	c.Unlock()
 
 	return nil
 }
@@ -0,0 +1,38 @@
+//go:build integration
+// +build integration
+
+package elasticsearch_test
+
+import (
+	"os"
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/interfaces/interfacestest"
+	"github.com/fajarardiyanto/flt-go-database/lib/elasticsearch"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+// TestElasticSearch runs the suite against the nodes of INTEGRATION_ELASTICSEARCH_URL,
+// e.g. http://127.0.0.1:9200.
+func TestElasticSearch(t *testing.T) {
+	url := os.Getenv("INTEGRATION_ELASTICSEARCH_URL")
+	if len(url) == 0 {
+		t.Skip("INTEGRATION_ELASTICSEARCH_URL not set")
+	}
+
+	logger := log.NewLib()
+	logger.Init("elasticsearch integration")
+
+	interfacestest.RunElasticSearchSuite(t, func(t *testing.T, index string) database.ElasticSearch {
+		client := elasticsearch.NewElasticSearch(t.Name(), logger, database.ElasticSearchProviderConfig{
+			Enable:    true,
+			URL:       url,
+			IndexName: index,
+		})
+		if err := client.ElasticSearch(); err != nil {
+			t.Fatalf("ElasticSearch: %v", err)
+		}
+		return client
+	})
+}
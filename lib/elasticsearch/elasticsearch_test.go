@@ -0,0 +1,47 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+func TestConnectSendsResolvedCredentials(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		got = append(got, username+":"+password)
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":{"number":"7.17.1"}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("FLT_ES_TEST_PASSWORD", "first")
+
+	lg := log.NewLib()
+	lg.Init("Test ElasticSearch")
+
+	c := NewElasticSearch("credentials", lg, interfaces.ElasticSearchProviderConfig{
+		Enable:   true,
+		URL:      server.URL,
+		Username: "elastic",
+		Password: "env:FLT_ES_TEST_PASSWORD",
+	}).(*ElasticSearch)
+
+	if err := c.connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FLT_ES_TEST_PASSWORD", "rotated")
+	if err := c.connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) < 2 || got[0] != "elastic:first" || got[len(got)-1] != "elastic:rotated" {
+		t.Errorf("credentials = %q, want elastic:first then elastic:rotated", got)
+	}
+}
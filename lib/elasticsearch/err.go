@@ -0,0 +1,36 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+)
+
+// responseError returns the error of a failed response with the type and reason
+// read from its body, a 404 wraps ErrNotFound and a 409 ErrConflict.
+func (c *ElasticSearch) responseError(op string, res *esapi.Response) error {
+	var e struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+
+	cause := fmt.Errorf("%s", res.Status())
+	if err := json.NewDecoder(res.Body).Decode(&e); err == nil && len(e.Error.Type) != 0 {
+		cause = fmt.Errorf("%s: %s", e.Error.Type, e.Error.Reason)
+	}
+
+	return dberrors.WithStatus("elasticsearch", c.tag, op, res.StatusCode, cause)
+}
+
+// client returns the current client, ErrNotConnected before it connects.
+func (c *ElasticSearch) client(op string) (*elasticsearch.Client, error) {
+	elastic := c.Elastic()
+	if elastic == nil {
+		return nil, dberrors.New("elasticsearch", c.tag, op, dberrors.ErrNotConnected)
+	}
+	return elastic, nil
+}
@@ -1,19 +1,32 @@
 package elasticsearch
 
 import (
-	"fmt"
+	"context"
 	"strings"
 )
 
 func (c *ElasticSearch) CreateIndex(name string, mapping string) error {
-	res, err := c.elastic.Indices.Create(name, c.elastic.Indices.Create.WithBody(strings.NewReader(mapping)))
+	return c.createIndex(context.Background(), name, mapping)
+}
+
+func (c *ElasticSearch) createIndex(ctx context.Context, name string, mapping string) error {
+	elastic, err := c.client("create index")
+	if err != nil {
+		return err
+	}
+
+	res, err := elastic.Indices.Create(name, elastic.Indices.Create.WithBody(strings.NewReader(mapping)),
+		elastic.Indices.Create.WithContext(ctx))
 	if err != nil {
 		c.log.Error(err)
 		return err
 	}
+	defer res.Body.Close()
+
 	if res.IsError() {
-		c.log.Error(res)
-		return fmt.Errorf("error: %s", res)
+		err := c.responseError("create index", res)
+		c.log.Error(err)
+		return err
 	}
 	return nil
 }
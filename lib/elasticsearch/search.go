@@ -1,20 +1,33 @@
 package elasticsearch
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/redact"
 	"io"
 	"strings"
 )
 
 func (c *ElasticSearch) Search(config interfaces.ElasticSearchOptions) (*interfaces.SearchResultsElasticSearch, error) {
+	return c.search(context.Background(), config)
+}
+
+func (c *ElasticSearch) search(ctx context.Context, config interfaces.ElasticSearchOptions) (*interfaces.SearchResultsElasticSearch, error) {
 	var result interfaces.SearchResultsElasticSearch
 
+	elastic, err := c.client("search")
+	if err != nil {
+		return nil, err
+	}
+
 	q := c.BuildQuery(config)
-	res, err := c.elastic.Search(
-		c.elastic.Search.WithIndex(c.config.IndexName),
-		c.elastic.Search.WithBody(q),
+	res, err := elastic.Search(
+		elastic.Search.WithIndex(c.config.IndexName),
+		elastic.Search.WithBody(q),
+		elastic.Search.WithContext(ctx),
 	)
 	if err != nil {
 		c.log.Error(err)
@@ -23,17 +36,13 @@ func (c *ElasticSearch) Search(config interfaces.ElasticSearchOptions) (*interfa
 	defer res.Body.Close()
 
 	if res.StatusCode == 404 {
-		c.log.Error(fmt.Errorf("%s", "Index Not Found"))
-		return &result, fmt.Errorf("%s", "Index Not Found")
+		err := c.responseError("search", res)
+		c.log.Error(err)
+		return &result, err
 	}
 
 	if res.IsError() {
-		var e map[string]interface{}
-		if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
-			c.log.Error(err)
-			return nil, err
-		}
-
+		err := c.responseError("search", res)
 		c.log.Error(err)
 		return nil, err
 	}
@@ -45,8 +54,7 @@ func (c *ElasticSearch) Search(config interfaces.ElasticSearchOptions) (*interfa
 	}
 
 	if len(r.Hits.Hits) < 1 {
-		c.log.Error(err)
-		return nil, fmt.Errorf("%s", "Data Not Found")
+		return nil, dberrors.New("elasticsearch", c.tag, "search", dberrors.ErrNotFound)
 	}
 
 	for _, h := range r.Hits.Hits {
@@ -83,8 +91,7 @@ func (c *ElasticSearch) BuildQuery(config interfaces.ElasticSearchOptions) io.Re
 
 	b.WriteString("\n}")
 
-	//fmt.Printf("%s\n", b.String())
-	c.log.Info(b.String())
+	c.log.Debug("%s", redact.Payload([]byte(b.String())))
 	return strings.NewReader(b.String())
 }
 
@@ -4,23 +4,30 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
 )
 
 func (c *ElasticSearch) Create(index string, id string, values interface{}) error {
+	return c.create(context.Background(), index, id, values)
+}
+
+func (c *ElasticSearch) create(ctx context.Context, index string, id string, values interface{}) error {
+	elastic, err := c.client("create")
+	if err != nil {
+		return err
+	}
+
 	payload, err := json.Marshal(values)
 	if err != nil {
 		c.log.Error(err)
 		return err
 	}
 
-	ctx := context.Background()
 	res, err := esapi.CreateRequest{
 		Index:      index,
 		DocumentID: id,
 		Body:       bytes.NewReader(payload),
-	}.Do(ctx, c.elastic)
+	}.Do(ctx, elastic)
 	if err != nil {
 		c.log.Error(err)
 		return err
@@ -28,15 +35,9 @@ func (c *ElasticSearch) Create(index string, id string, values interface{}) erro
 	defer res.Body.Close()
 
 	if res.IsError() {
-		var e map[string]interface{}
-		if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
-			c.log.Error(err)
-			return err
-		}
-
-		er := fmt.Sprintf("[%s] %s: %s", res.Status(), e["error"].(map[string]interface{})["type"], e["error"].(map[string]interface{})["reason"])
-		c.log.Error(er)
-		return fmt.Errorf(er)
+		err := c.responseError("create", res)
+		c.log.Error(err)
+		return err
 	}
 
 	return nil
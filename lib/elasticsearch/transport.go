@@ -0,0 +1,44 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type transport struct {
+	tag  string
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracing.Start(req.Context(), "elasticsearch "+req.Method, trace.SpanKindClient,
+		semconv.DBSystemElasticsearch,
+		semconv.HTTPMethodKey.String(req.Method),
+		semconv.HTTPTargetKey.String(req.URL.Path),
+		tracing.TagKey.String(t.tag))
+
+	now := time.Now()
+	res, err := t.base.RoundTrip(req.WithContext(ctx))
+
+	code := 0
+	if res != nil {
+		code = res.StatusCode
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(code))
+		if err == nil && code >= http.StatusInternalServerError {
+			tracing.End(span, fmt.Errorf("elasticsearch responded with %s", res.Status))
+		} else {
+			tracing.End(span, err)
+		}
+	} else {
+		tracing.End(span, err)
+	}
+	metrics.ElasticSearchRequest(t.tag, req.Method, code, time.Since(now))
+
+	return res, err
+}
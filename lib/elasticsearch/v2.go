@@ -0,0 +1,49 @@
+package elasticsearch
+
+import (
+	"context"
+	"github.com/elastic/go-elasticsearch/v7"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	databasev2 "github.com/fajarardiyanto/flt-go-database/interfaces/v2"
+)
+
+type clientV2 struct {
+	c *ElasticSearch
+}
+
+// V2 returns the client with the context-first interface.
+func (c *ElasticSearch) V2() databasev2.ElasticSearch {
+	return clientV2{c}
+}
+
+func (v clientV2) Elastic() *elasticsearch.Client {
+	return v.c.Elastic()
+}
+
+func (v clientV2) Connect(ctx context.Context) error {
+	return v.c.start(ctx)
+}
+
+func (v clientV2) Search(ctx context.Context, config database.ElasticSearchOptions) (*database.SearchResultsElasticSearch, error) {
+	return v.c.search(ctx, config)
+}
+
+func (v clientV2) CreateIndex(ctx context.Context, name string, mapping string) error {
+	return v.c.createIndex(ctx, name, mapping)
+}
+
+func (v clientV2) Create(ctx context.Context, index string, id string, values interface{}) error {
+	return v.c.create(ctx, index, id, values)
+}
+
+func (v clientV2) Delete(ctx context.Context, id string) error {
+	return v.c.delete(ctx, id)
+}
+
+func (v clientV2) Ping(ctx context.Context) error {
+	return v.c.Ping(ctx)
+}
+
+func (v clientV2) Close(ctx context.Context) error {
+	return v.c.Close(ctx)
+}
@@ -0,0 +1,13 @@
+package lib
+
+import (
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/events"
+)
+
+// OnEvent calls the handler when a client of the process connects, loses its
+// connection, retries, gives up or when a consumer subscribes. The handler must not
+// block.
+func OnEvent(handler func(database.Event)) {
+	events.Subscribe(handler)
+}
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+var (
+	handlers []func(database.Event)
+	mutex    sync.RWMutex
+)
+
+// Subscribe adds a handler called with every event of every client. The handlers
+// run on the goroutine of the client and must not block.
+func Subscribe(handler func(database.Event)) {
+	if handler == nil {
+		return
+	}
+
+	mutex.Lock()
+	handlers = append(handlers, handler)
+	mutex.Unlock()
+}
+
+// Emit calls the handlers with the event, a panicking handler does not stop the
+// others.
+func Emit(e database.Event) {
+	mutex.RLock()
+	current := handlers
+	mutex.RUnlock()
+
+	if len(current) == 0 {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	for _, handler := range current {
+		call(handler, e)
+	}
+}
+
+func call(handler func(database.Event), e database.Event) {
+	defer func() {
+		_ = recover()
+	}()
+	handler(e)
+}
+
+func Connected(provider, tag string) {
+	Emit(database.Event{Type: database.EventConnected, Provider: provider, Tag: tag})
+}
+
+func Disconnected(provider, tag string, err error) {
+	Emit(database.Event{Type: database.EventDisconnected, Provider: provider, Tag: tag, Error: err})
+}
+
+func Reconnecting(provider, tag string, attempt int, delay time.Duration, err error) {
+	Emit(database.Event{Type: database.EventReconnecting, Provider: provider, Tag: tag,
+		Attempt: attempt, Delay: delay, Error: err})
+}
+
+func GaveUp(provider, tag string, attempt int, err error) {
+	Emit(database.Event{Type: database.EventGaveUp, Provider: provider, Tag: tag,
+		Attempt: attempt, Error: err})
+}
+
+func ConsumerSubscribed(provider, tag, destination string) {
+	Emit(database.Event{Type: database.EventConsumerSubscribed, Provider: provider, Tag: tag,
+		Destination: destination})
+}
@@ -0,0 +1,93 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+// Health pings every registered client concurrently and reports its status. The
+// last error of a client is kept until the next failure, even when it recovers.
+func (m *Modules) Health(ctx context.Context) database.HealthReport {
+	targets := m.clients()
+	statuses := make([]database.HealthStatus, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target registered) {
+			defer wg.Done()
+
+			now := time.Now()
+			err := target.client.Ping(ctx)
+			status := database.HealthStatus{
+				Provider:  target.provider,
+				Tag:       target.tag,
+				Status:    database.HealthUp,
+				Latency:   time.Since(now),
+				CheckedAt: now,
+			}
+
+			key := target.provider + "/" + target.tag
+			m.Lock()
+			if err != nil {
+				status.Status = database.HealthDown
+				status.Error = err.Error()
+				m.lastErrors[key] = err.Error()
+			}
+			status.LastError = m.lastErrors[key]
+			m.Unlock()
+
+			statuses[i] = status
+		}(i, target)
+	}
+	wg.Wait()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Provider != statuses[j].Provider {
+			return statuses[i].Provider < statuses[j].Provider
+		}
+		return statuses[i].Tag < statuses[j].Tag
+	})
+
+	report := database.HealthReport{Status: database.HealthUp, Clients: statuses}
+	for _, status := range statuses {
+		if status.Status != database.HealthUp {
+			report.Status = database.HealthDown
+			break
+		}
+	}
+
+	return report
+}
+
+// HealthHandler serves the health report as JSON, answering 503 when any client is
+// down. It is meant for readiness probes.
+func HealthHandler(db database.Database, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		report := db.Health(ctx)
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != database.HealthUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// LivenessHandler always answers 200, a broken dependency must not restart the
+// process.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"up"}`))
+	})
+}
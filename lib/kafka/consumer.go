@@ -1,32 +1,42 @@
 package kafka
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
 	"fmt"
 	"github.com/confluentinc/confluent-kafka-go/kafka"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/backoff"
+	"github.com/fajarardiyanto/flt-go-database/lib/compress"
+	"github.com/fajarardiyanto/flt-go-database/lib/events"
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
+	"github.com/fajarardiyanto/flt-go-database/lib/retry"
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
-	"github.com/fajarardiyanto/flt-go-utils/hash"
 	"github.com/linkedin/goavro/v2"
 	"github.com/riferrei/srclient"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/ratelimit"
 	"google.golang.org/grpc/metadata"
-	"log"
-	"os"
-	"os/signal"
 	"sync"
-	"syscall"
+	"time"
 )
 
 type Consumer struct {
-	callback database.ConsumerCallback
-	options  database.KafkaOptions
-	config   database.KafkaProviderConfig
-	store    *Stores
-	logger   logger.Logger
-	limit    ratelimit.Limiter
+	tag        string
+	callback   database.ConsumerCallback
+	options    database.KafkaOptions
+	config     database.KafkaProviderConfig
+	store      *Stores
+	logger     logger.Logger
+	limit      ratelimit.Limiter
+	consumer   *kafka.Consumer
+	attempt    int
+	subscribed bool
+	stop       chan struct{}
+	done       chan struct{}
+	inflight   sync.WaitGroup
 	sync.RWMutex
 }
 
@@ -45,70 +55,242 @@ func NewConsumer(lg logger.Logger, config database.KafkaProviderConfig,
 		config:   config,
 		logger:   lg,
 		limit:    ratelimit.New(limiter),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
 	}
 }
 
+func (c *Consumer) GetMetadata(timeoutMs int) (*kafka.Metadata, error) {
+	c.RLock()
+	consumer := c.consumer
+	c.RUnlock()
+
+	if consumer == nil {
+		return nil, dberrors.New("kafka", c.tag, "metadata", dberrors.ErrNotConnected)
+	}
+	return consumer.GetMetadata(nil, false, timeoutMs)
+}
+
+// Close stops polling, waits for the in-flight callbacks and closes the consumer.
+func (c *Consumer) Close(ctx context.Context) error {
+	c.Lock()
+	select {
+	case <-c.stop:
+	default:
+		close(c.stop)
+	}
+	c.Unlock()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Consumer) dispatch(msg database.Messages) {
+	if c.callback == nil {
+		return
+	}
+
+	ctx, span := tracing.Start(msg.Context(), c.options.Topic+" receive", trace.SpanKindConsumer,
+		semconv.MessagingSystemKey.String("kafka"),
+		semconv.MessagingDestinationKey.String(c.options.Topic),
+		semconv.MessagingOperationReceive,
+		tracing.TagKey.String(c.tag))
+	msg.SetContext(ctx)
+
+	if c.options.MultipleThread {
+		c.limit.Take()
+
+		// dispatch runs on the goroutine of Run, the count never grows while Run
+		// waits for it, Close waits for Run instead
+		c.inflight.Add(1)
+		go func() {
+			defer c.inflight.Done()
+			defer span.End()
+			c.callback(msg, database.ConsumerCallbackIsDone{
+				EndRequest: func() {
+				},
+			})
+		}()
+		return
+	}
+
+	defer span.End()
+	c.callback(msg, database.ConsumerCallbackIsDone{
+		EndRequest: func() {
+		},
+	})
+}
+
+func (c *Consumer) recordConsumed(consumer *kafka.Consumer, tp kafka.TopicPartition) {
+	if tp.Topic == nil {
+		return
+	}
+
+	lag := int64(-1)
+	if _, high, err := consumer.GetWatermarkOffsets(*tp.Topic, tp.Partition); err == nil && high >= 0 {
+		lag = high - int64(tp.Offset) - 1
+	}
+	metrics.KafkaConsumed(c.tag, *tp.Topic, tp.Partition, lag)
+}
+
+// Run consumes until the consumer is closed, the consumer is created again with the
+// backoff policy of the provider when it fails.
 func (c *Consumer) Run() {
+	defer close(c.done)
+
+	policy := backoff.FromConfig(c.config.Backoff, 0, -1)
+	for {
+		err := c.consume()
+		if err == nil {
+			break
+		}
+
+		c.Lock()
+		c.attempt++
+		attempt := c.attempt
+		subscribed := c.subscribed
+		c.subscribed = false
+		c.Unlock()
+
+		if subscribed {
+			events.Disconnected("kafka", c.tag, err)
+		}
+
+		c.logger.Error("[%s] %s", c.options.Topic, err)
+		if policy.Exhausted(attempt) {
+			c.logger.Error("[%s] giving up kafka consumer after %d attempts", c.options.Topic, attempt)
+			events.GaveUp("kafka", c.tag, attempt, err)
+			break
+		}
+
+		delay := policy.Delay(attempt)
+		c.logger.Warning("[%s] reconnecting kafka consumer in %s", c.options.Topic, delay)
+		events.Reconnecting("kafka", c.tag, attempt, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-c.stop:
+			timer.Stop()
+			c.inflight.Wait()
+			return
+		case <-timer.C:
+		}
+	}
+
+	c.inflight.Wait()
+}
+
+func (c *Consumer) consume() (err error) {
 	c.logger.Debug("Starting kafka consumer with topic %s, with group %s", c.options.Topic, c.options.Group)
-	config := createConsumerInit(c.logger, c.config, c.options)
+	cfg, err := credentials(c.config)
+	if err != nil {
+		return err
+	}
+
+	config := createConsumerInit(c.logger, cfg, c.options)
 	consumer, err := kafka.NewConsumer(config)
 	if err != nil {
-		c.logger.Error(err).Quit()
+		return err
 	}
 
+	c.Lock()
+	c.consumer = consumer
+	c.Unlock()
+
+	defer func() {
+		c.logger.Debug("Closing consumer")
+		c.Lock()
+		c.consumer = nil
+		c.Unlock()
+		if err := consumer.Close(); err != nil {
+			c.logger.Error(err)
+		}
+	}()
+
 	var schema *srclient.Schema
 	if len(c.config.Registry) != 0 && len(c.options.RegistryValue) != 0 {
-		_, schema, err = getLastSchema(c.config, c.options)
+		_, schema, err = getLastSchema(cfg, c.options)
 		if err != nil {
-			c.logger.Error(err).Quit()
+			return fmt.Errorf("failed to get schema: %w", err)
 		}
 	}
 
-	codec, err := goavro.NewCodec(schema.Schema())
-	if err != nil {
-		c.logger.Error("failed to get schema codec %s", err.Error()).Quit()
+	var codec *goavro.Codec
+	if schema != nil {
+		codec, err = goavro.NewCodec(schema.Schema())
+		if err != nil {
+			return fmt.Errorf("failed to get schema codec: %w", err)
+		}
 	}
 
-	if err := consumer.SubscribeTopics([]string{c.options.Topic}, nil); err != nil {
-		c.logger.Error(err).Quit()
+	topics := []string{c.options.Topic}
+	if c.options.Retry != nil {
+		retryTopic, _ := retry.Destinations(*c.options.Retry, c.options.Topic)
+		topics = append(topics, retryTopic)
 	}
 
-	run := true
+	if err := consumer.SubscribeTopics(topics, nil); err != nil {
+		return err
+	}
+
+	c.Lock()
+	c.attempt = 0
+	c.subscribed = true
+	c.Unlock()
+	events.ConsumerSubscribed("kafka", c.tag, c.options.Topic)
 
-	sigchan := make(chan os.Signal, 1)
-	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
+	run := true
+	paused := make(map[partition]time.Time)
 
 	for run {
 		select {
-		case sig := <-sigchan:
-			c.logger.Warning("Caught signal %s: terminating", sig.String())
+		case <-c.stop:
+			c.logger.Debug("Stopping kafka consumer with topic %s", c.options.Topic)
 			run = false
 		default:
+			c.resume(consumer, paused)
 			ev := consumer.Poll(10)
 			switch e := ev.(type) {
 			case *kafka.Message:
 				mdd := make(map[string]string)
 				mdd["content-type"] = "application/rabbitmq"
+				headers := make(map[string]interface{})
 				for _, s := range e.Headers {
 					mdd[s.Key] = string(s.Value)
+					headers[s.Key] = string(s.Value)
 				}
+
+				if notBefore := retry.NotBefore(headers); time.Now().Before(notBefore) {
+					err := c.pause(consumer, e.TopicPartition, notBefore, paused)
+					if err == nil {
+						continue
+					}
+					c.logger.Error("[%s] can not pause partition %d until the retry: %s", c.options.Topic, e.TopicPartition.Partition, err)
+				}
+
+				c.recordConsumed(consumer, e.TopicPartition)
 				md := metadata.New(mdd)
-				ctx := metadata.NewIncomingContext(context.Background(), md)
+				ctx := tracing.Extract(metadata.NewIncomingContext(context.Background(), md), headers)
+
+				value, err := compress.Decompress(compress.Header(headers), e.Value)
+				if err != nil {
+					c.logger.Error("Failed to decompress message %s", err)
+					continue
+				}
 
 				var data []byte
 				if schema != nil {
-					dataRaw := e.Value[5:]
-					//debug results
-					os.MkdirAll("/tmp/", 0755)
-					if err := os.WriteFile(fmt.Sprintf("/tmp/%s", hash.CreateRandomId(10)), dataRaw, 0755); err != nil {
-						log.Println(err)
-					}
-
-					if codec == nil {
+					if len(value) < 5 {
+						c.logger.Error("[%s] message of %d bytes too short for the schema registry", c.options.Topic, len(value))
+					} else if codec == nil {
 						err := fmt.Errorf("failed to get codec scheme, codec is nil")
 						c.logger.Error(err)
 					} else {
-						native, _, err := codec.NativeFromBinary(dataRaw)
+						native, _, err := codec.NativeFromBinary(value[5:])
 						if err == nil {
 							value, err := codec.TextualFromNative(nil, native)
 							if err == nil {
@@ -122,71 +304,40 @@ func (c *Consumer) Run() {
 					}
 
 				} else {
-					data = e.Value
+					data = value
 				}
 
-				switch c.options.Encoding {
-				case database.EncodingBase64Gob:
-					if data, err := base64.StdEncoding.DecodeString(string(data)); err == nil {
-						msg := database.NewEncoder(bytes.NewBuffer(data),
-							c.options.Topic, c.options.Group,
-							database.EncodingBase64Gob)
-
-						msg.SetContext(ctx)
+				id, _ := headers[database.HeaderMessageID].(string)
+				msg := database.NewMessage(data,
+					c.options.Topic, c.options.Group,
+					c.options.Encoding, database.MessageInfo{
+						ID:        id,
+						Headers:   headers,
+						Timestamp: e.Timestamp,
+						Metadata: database.MessageMetadata{Tag: c.tag, Kafka: &database.KafkaMetadata{
+							Topic:     *e.TopicPartition.Topic,
+							Partition: e.TopicPartition.Partition,
+							Offset:    int64(e.TopicPartition.Offset),
+							Key:       e.Key,
+							Value:     value,
+						}},
+					})
 
-						if c.callback != nil {
-							if c.options.MultipleThread {
-								c.limit.Take()
-
-								go c.callback(msg, database.ConsumerCallbackIsDone{
-									EndRequest: func() {
-									},
-								})
-							} else {
-								c.callback(msg, database.ConsumerCallbackIsDone{
-									EndRequest: func() {
-									},
-								})
-							}
-
-						}
-					} else {
-						c.logger.Error(err)
-					}
-				default:
-					msg := database.NewEncoder(bytes.NewBuffer(data),
-						c.options.Topic, c.options.Group,
-						c.options.Encoding)
-
-					msg.SetContext(ctx)
-
-					if c.callback != nil {
-						if c.options.MultipleThread {
-							c.limit.Take()
-
-							go c.callback(msg, database.ConsumerCallbackIsDone{
-								EndRequest: func() {
-								},
-							})
-						} else {
-							c.callback(msg, database.ConsumerCallbackIsDone{
-								EndRequest: func() {
-								},
-							})
-						}
-
-					}
-				}
+				msg.SetContext(ctx)
+				c.dispatch(msg)
 			case kafka.Error:
+				// the consumer is created again, its credentials resolved again
+				if e.IsFatal() || e.Code() == kafka.ErrAuthentication {
+					return e
+				}
+				if e.Code() == kafka.ErrAllBrokersDown {
+					events.Disconnected("kafka", c.tag, e)
+				}
 				c.logger.Error(e.Error())
-				run = false
 
 			}
 		}
 	}
 
-	c.logger.Debug("Closing consumer")
-	consumer.Close()
-	os.Exit(0)
-
+	return nil
 }
@@ -2,20 +2,30 @@ package kafka
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"github.com/confluentinc/confluent-kafka-go/kafka"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/middleware"
+	"github.com/fajarardiyanto/flt-go-database/lib/retry"
+	"github.com/fajarardiyanto/flt-go-database/lib/secret"
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	gutils "github.com/fajarardiyanto/flt-go-utils/grpc"
 	"github.com/fajarardiyanto/flt-go-utils/hash"
 	"github.com/google/uuid"
 	"github.com/riferrei/srclient"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 	"strings"
 	"sync"
+	"time"
 )
 
 var storesCallback *Stores
 
+var errTopicRequired = errors.New("topic is required")
+
 func init() {
 	storesCallback = NewStore()
 }
@@ -35,7 +45,7 @@ func init() {
 }
 
 func NewKafka(tag string, lo logger.Logger, config database.KafkaProviderConfig) database.Kafka {
-	id := hash.CreateSmallHash(10, config.Host, config.Username, config.Password)
+	id := hash.CreateSmallHash(10, strings.ToLower(tag), config.Host, config.Username, config.Password)
 
 	if vals, ok := storesCallback.LoadClient(id); ok {
 		return vals
@@ -51,17 +61,53 @@ func NewKafka(tag string, lo logger.Logger, config database.KafkaProviderConfig)
 	return msq
 }
 
+func LoadKafka(tag string) (database.Kafka, bool) {
+	client, ok := storesCallback.LoadClientByTag(strings.ToLower(tag))
+	if !ok {
+		return nil, false
+	}
+	return client, true
+}
+
 func (c *Kafka) Consumer(options database.KafkaOptions, callback database.ConsumerCallback) {
+	if err := c.addConsumer(options, callback); err != nil {
+		c.log.Error(err)
+	}
+}
+
+// Handle consumes like Consumer, with a retry policy the messages the handler failed
+// are produced on the retry topic, then on the dead letter topic, by the producer of
+// the client. Otherwise the errors are logged.
+func (c *Kafka) Handle(options database.KafkaOptions, handler database.ConsumerHandler) {
+	if err := c.addHandler(options, handler); err != nil {
+		c.log.Error(err)
+	}
+}
+
+func (c *Kafka) addHandler(options database.KafkaOptions, handler database.ConsumerHandler) error {
+	if options.Retry != nil {
+		handler = retry.Handler(*options.Retry, retryPublisher{client: c, options: options}, handler)
+	}
+
+	return c.addConsumer(options, func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+		if err := handler(msg, done); err != nil {
+			c.log.Error("[%s] message %s failed: %s", options.Topic, msg.ID(), err)
+		}
+	})
+}
+
+func (c *Kafka) addConsumer(options database.KafkaOptions, callback database.ConsumerCallback) error {
 	if !c.config.Enable {
-		c.log.Error("Kafka is disabled").Quit()
+		return dberrors.New("kafka", c.tag, "consumer", dberrors.ErrDisabled)
 	}
 
 	if len(options.Topic) == 0 {
-		c.log.Error("Topic is required").Quit()
+		return dberrors.New("kafka", c.tag, "consumer", errTopicRequired)
 	}
 
 	consumer := NewConsumer(c.log, c.config, options,
-		callback, storesCallback)
+		middleware.Chain(callback, options.Middlewares...), storesCallback)
+	consumer.tag = c.tag
 
 	c.Lock()
 	c.consumer[options.Topic] = consumer
@@ -69,21 +115,39 @@ func (c *Kafka) Consumer(options database.KafkaOptions, callback database.Consum
 
 	go consumer.Run()
 
+	return nil
 }
 
 func (c *Kafka) Producer(isReady database.ProducerIsReady) {
+	if err := c.addProducer(isReady); err != nil {
+		c.log.Error(err)
+	}
+}
+
+func (c *Kafka) addProducer(isReady database.ProducerIsReady) error {
 	if !c.config.Enable {
-		c.log.Error("Kafka is disabled").Quit()
+		return dberrors.New("kafka", c.tag, "producer", dberrors.ErrDisabled)
 	}
 
 	c.Lock()
+	if c.producer != nil {
+		c.Unlock()
+		// the producer already runs, e.g. started by LoadFromConfig
+		if isReady != nil {
+			go isReady()
+		}
+		return nil
+	}
 	c.producer = NewProducer(c.log, c.config, storesCallback)
+	c.producer.tag = c.tag
+	producer := c.producer
 	c.Unlock()
-	go c.producer.Run(isReady)
+	go producer.Run(isReady)
 
+	return nil
 }
 
-func (c *Kafka) Push(ctx context.Context, id string, options database.KafkaOptions, body interface{}, cb database.ConsumerCallback) error {
+func (c *Kafka) Push(ctx context.Context, id string, options database.KafkaOptions, body interface{}, cb database.ConsumerCallback) (err error) {
 	c.RLock()
 	producer := c.producer
 	c.RUnlock()
@@ -96,11 +160,19 @@ func (c *Kafka) Push(ctx context.Context, id string, options database.KafkaOptio
 		headers["uber-trace-id"] = meta.Get("uber-trace-id")
 	}
 
+	spanCtx, span := tracing.Start(ctx, options.Topic+" send", trace.SpanKindProducer,
+		semconv.MessagingSystemKey.String("kafka"),
+		semconv.MessagingDestinationKey.String(options.Topic),
+		tracing.TagKey.String(c.tag))
+	defer func() {
+		tracing.End(span, err)
+	}()
+	tracing.Inject(spanCtx, headers)
+
 	if producer != nil {
 
 		if cb == nil {
-			producer.SendingData(id, options, body, headers, nil)
-			return nil
+			return producer.SendingData(id, options, body, headers, nil)
 		}
 
 		ctx, cancel := context.WithCancel(ctx)
@@ -122,11 +194,13 @@ func (c *Kafka) Push(ctx context.Context, id string, options database.KafkaOptio
 			},
 		}
 
-		producer.SendingData(id, options, body, headers, func(s database.Messages,
+		if err := producer.SendingData(id, options, body, headers, func(s database.Messages,
 			ccid database.ConsumerCallbackIsDone) {
 			doneCtx = ccid
 			cb(s, done)
-		})
+		}); err != nil {
+			return err
+		}
 
 		<-ctx.Done()
 
@@ -134,7 +208,61 @@ func (c *Kafka) Push(ctx context.Context, id string, options database.KafkaOptio
 
 	}
 
-	return fmt.Errorf("kafka not ready")
+	return dberrors.New("kafka", c.tag, "push", dberrors.ErrNotConnected)
+}
+
+func (c *Kafka) Ping(ctx context.Context) error {
+	if !c.config.Enable {
+		return dberrors.New("kafka", c.tag, "ping", dberrors.ErrDisabled)
+	}
+
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	c.RLock()
+	producer := c.producer
+	consm := c.consumer
+	c.RUnlock()
+
+	if producer != nil {
+		_, err := producer.GetMetadata(int(timeout.Milliseconds()))
+		return err
+	}
+
+	for _, val := range consm {
+		_, err := val.GetMetadata(int(timeout.Milliseconds()))
+		return err
+	}
+
+	return dberrors.New("kafka", c.tag, "ping", dberrors.ErrNotConnected)
+}
+
+// Close stops every consumer, waiting for their in-flight callbacks, then flushes and
+// closes the producer.
+func (c *Kafka) Close(ctx context.Context) error {
+	c.RLock()
+	producer := c.producer
+	consm := c.consumer
+	c.RUnlock()
+
+	var err error
+	for _, val := range consm {
+		if e := val.Close(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	if producer != nil {
+		if e := producer.Close(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	storesCallback.DeleteClient(c)
+
+	return err
 }
 
 func createConsumerInit(lo logger.Logger, cfg database.KafkaProviderConfig, options database.KafkaOptions) (config *kafka.ConfigMap) {
@@ -195,6 +323,8 @@ func createConsumerInit(lo logger.Logger, cfg database.KafkaProviderConfig, opti
 		config.SetKey("sasl.password", cfg.Password)
 	}
 
+	setTLS(config, cfg)
+
 	return config
 }
 
@@ -245,9 +375,46 @@ func createProducerInit(lo logger.Logger, cfg database.KafkaProviderConfig) (con
 		config.SetKey("sasl.password", cfg.Password)
 	}
 
+	setTLS(config, cfg)
+
 	return config
 }
 
+// credentials returns the config with its secrets resolved, on every connection so
+// a rotated secret is picked up.
+func credentials(cfg database.KafkaProviderConfig) (database.KafkaProviderConfig, error) {
+	err := secret.ResolveAll(context.Background(), &cfg.Username, &cfg.Password, &cfg.Registry)
+	return cfg, err
+}
+
+// setTLS sets the ssl.* keys of librdkafka, ServerName and MinVersion of the TLS
+// config are not supported.
+func setTLS(config *kafka.ConfigMap, cfg database.KafkaProviderConfig) {
+	if !cfg.TLS.Enable {
+		return
+	}
+
+	if len(cfg.Username) == 0 || len(cfg.Password) == 0 {
+		config.SetKey("security.protocol", "SSL")
+	}
+
+	if len(cfg.TLS.CAFile) != 0 {
+		config.SetKey("ssl.ca.location", cfg.TLS.CAFile)
+	}
+
+	if len(cfg.TLS.CertFile) != 0 && len(cfg.TLS.KeyFile) != 0 {
+		config.SetKey("ssl.certificate.location", cfg.TLS.CertFile)
+		config.SetKey("ssl.key.location", cfg.TLS.KeyFile)
+	}
+
+	if cfg.TLS.InsecureSkipVerify {
+		config.SetKey("enable.ssl.certificate.verification", false)
+		config.SetKey("ssl.endpoint.identification.algorithm", "none")
+	} else {
+		config.SetKey("ssl.endpoint.identification.algorithm", "https")
+	}
+}
+
 func getLastSchema(cfg database.KafkaProviderConfig, options database.KafkaOptions) (*srclient.SchemaRegistryClient, *srclient.Schema, error) {
 	schemaRegistryClient := srclient.CreateSchemaRegistryClient(cfg.Registry)
 	if len(cfg.Username) != 0 && len(cfg.Password) != 0 {
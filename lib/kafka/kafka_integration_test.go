@@ -0,0 +1,30 @@
+//go:build integration
+// +build integration
+
+package kafka_test
+
+import (
+	"os"
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/interfaces/interfacestest"
+	"github.com/fajarardiyanto/flt-go-database/lib/kafka"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+// TestKafka runs the suite against the brokers of INTEGRATION_KAFKA_HOST, e.g.
+// 127.0.0.1:9092, the topics are expected to be created on first use.
+func TestKafka(t *testing.T) {
+	host := os.Getenv("INTEGRATION_KAFKA_HOST")
+	if len(host) == 0 {
+		t.Skip("INTEGRATION_KAFKA_HOST not set")
+	}
+
+	logger := log.NewLib()
+	logger.Init("kafka integration")
+
+	interfacestest.RunKafkaSuite(t, func(t *testing.T) database.Kafka {
+		return kafka.NewKafka(t.Name(), logger, database.KafkaProviderConfig{Enable: true, Host: host})
+	})
+}
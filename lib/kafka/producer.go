@@ -1,17 +1,16 @@
 package kafka
 
 import (
-	"bytes"
-	"encoding/base64"
-	"encoding/gob"
-	"encoding/json"
+	"context"
 	"github.com/confluentinc/confluent-kafka-go/kafka"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/compress"
+	"github.com/fajarardiyanto/flt-go-database/lib/events"
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
+	"github.com/fajarardiyanto/flt-go-database/lib/redact"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	"github.com/fajarardiyanto/flt-go-utils/hash"
-	databaseproto "github.com/fajarardiyanto/module-proto/go/modules/database"
-	"google.golang.org/protobuf/proto"
-	"google.golang.org/protobuf/types/known/anypb"
 	"sync"
 	"time"
 )
@@ -24,11 +23,14 @@ type MsgSend struct {
 }
 
 type Producer struct {
+	tag     string
 	config  database.KafkaProviderConfig
 	logger  logger.Logger
 	store   *Stores
 	pending chan MsgSend
 	p       *kafka.Producer
+	sasl    [2]string
+	closed  bool
 	sync.RWMutex
 }
 
@@ -47,105 +49,204 @@ func NewProducer(lg logger.Logger,
 
 func (c *Producer) Run(isReady database.ProducerIsReady) (err error) {
 	c.logger.Debug("Starting kafka producer")
-	config := createProducerInit(c.logger, c.config)
-	c.p, err = kafka.NewProducer(config)
+	producer, err := c.dial()
 	if err != nil {
-		c.logger.Error(err).Quit()
+		c.logger.Error(err)
+		return err
 	}
+	events.Connected("kafka", c.tag)
 
 	go func() {
 		time.Sleep(1 * time.Second)
 		c.logger.Debug("Starting kafka producer is ready to use")
-		isReady()
+		if isReady != nil {
+			isReady()
+		}
 	}()
 
-	for e := range c.p.Events() {
+	for producer != nil {
+		producer = c.serve(producer)
+	}
+
+	return nil
+
+}
+
+// dial creates a producer with the credentials resolved again and makes it the
+// current one.
+func (c *Producer) dial() (*kafka.Producer, error) {
+	cfg, err := credentials(c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := kafka.NewProducer(createProducerInit(c.logger, cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		producer.Close()
+		return nil, dberrors.New("kafka", c.tag, "produce", dberrors.ErrNotConnected)
+	}
+	c.p = producer
+	c.sasl = [2]string{cfg.Username, cfg.Password}
+	c.Unlock()
+
+	return producer, nil
+}
+
+// rotated reports whether the credentials resolve to other values than the ones of
+// the current producer.
+func (c *Producer) rotated() bool {
+	cfg, err := credentials(c.config)
+	if err != nil {
+		c.logger.Error(err)
+		return false
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+	return c.sasl != [2]string{cfg.Username, cfg.Password}
+}
+
+// serve reads the events of the producer until it is closed. When the broker rejects
+// credentials rotated meanwhile, the producer replacing it is returned.
+func (c *Producer) serve(producer *kafka.Producer) *kafka.Producer {
+	for e := range producer.Events() {
 		switch ev := e.(type) {
 		case *kafka.Message:
 			m := ev
 			if m.TopicPartition.Error != nil {
+				if m.TopicPartition.Topic != nil {
+					metrics.KafkaDeliveryFailed(c.tag, *m.TopicPartition.Topic)
+				}
 				c.logger.Error("Delivery failed: %v", m.TopicPartition.Error)
 			} else {
 				c.logger.Debug("Delivered message to topic %s [%d] at offset %v",
 					*m.TopicPartition.Topic, m.TopicPartition.Partition, m.TopicPartition.Offset)
 			}
 		case kafka.Error:
+			if ev.Code() == kafka.ErrAllBrokersDown {
+				events.Disconnected("kafka", c.tag, ev)
+			}
 			c.logger.Error("Error: %v", ev)
+
+			if ev.Code() == kafka.ErrAuthentication && c.rotated() {
+				next, err := c.dial()
+				if err != nil {
+					c.logger.Error(err)
+					continue
+				}
+				c.logger.Warning("Kafka producer created again with the rotated credentials")
+				go producer.Close()
+				return next
+			}
 		default:
 			c.logger.Debug("Ignored event: %s", ev)
 		}
 	}
-
 	return nil
+}
 
+// Close flushes the outstanding messages until the context is done, then closes
+// the producer.
+func (c *Producer) Close(ctx context.Context) error {
+	c.Lock()
+	producer := c.p
+	c.p = nil
+	c.closed = true
+	c.Unlock()
+
+	if producer == nil {
+		return nil
+	}
+	defer producer.Close()
+
+	for producer.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			c.logger.Warning("Closing kafka producer with %d unflushed messages", producer.Len())
+			return err
+		}
+		producer.Flush(100)
+	}
+
+	return nil
 }
 
-func (c *Producer) SendingData(id string, options database.KafkaOptions, body interface{}, headers map[string]interface{}, cb database.ConsumerCallback) {
+func (c *Producer) GetMetadata(timeoutMs int) (*kafka.Metadata, error) {
+	c.RLock()
+	producer := c.p
+	c.RUnlock()
 
-	var data []byte
+	if producer == nil {
+		return nil, dberrors.New("kafka", c.tag, "metadata", dberrors.ErrNotConnected)
+	}
+	return producer.GetMetadata(nil, false, timeoutMs)
+}
+
+// SendingData produces the message, the errors to encode, compress or produce it are
+// returned.
+func (c *Producer) SendingData(id string, options database.KafkaOptions, body interface{}, headers map[string]interface{}, cb database.ConsumerCallback) error {
 
 	if len(id) == 0 {
 		id = hash.CreateRandomId(10)
 	}
 
-	if options.Encoding == database.EncodingBase64Gob {
-		bt := bytes.NewBuffer(nil)
-		defer bt.Reset()
-		if err := gob.NewEncoder(bt).Encode(body); err == nil {
-			data = []byte(base64.StdEncoding.EncodeToString(bt.Bytes()))
-		} else {
-			c.logger.Error("Failed to encode gob %s", err)
-		}
-	} else if options.Encoding == database.EncodingProto {
-		sendData := &databaseproto.SendData{
-			ID: id,
-		}
-		if val, ok := body.(proto.Message); ok {
-			if any, err := anypb.New(val); err == nil {
-				sendData.Data = any
-			}
-		}
+	data, err := database.Encode(options.Encoding, id, body)
+	if err != nil {
+		return dberrors.New("kafka", c.tag, "encode", err)
+	}
 
-		if da, err := proto.Marshal(sendData); err == nil {
-			data = da
-		}
+	data, contentEncoding, err := compress.Compress(options.Compression, options.CompressionThreshold, data)
+	if err != nil {
+		return dberrors.New("kafka", c.tag, "compress", err)
+	}
 
-	} else if options.Encoding == database.EncodingJSON {
-		data, _ = json.Marshal(body)
-	} else if options.Encoding == database.EncodingNone {
-		if val, ok := body.(string); ok {
-			data = []byte(val)
-		} else if val, ok := body.([]byte); ok {
-			data = val
-		}
+	if headers == nil {
+		headers = make(map[string]interface{})
+	}
+	headers[database.HeaderMessageID] = id
+	headers[database.HeaderContentType] = database.ContentTypeOf(options.Encoding)
+	if len(contentEncoding) != 0 {
+		headers[database.HeaderContentEncoding] = contentEncoding
 	}
 
-	if len(data) != 0 {
-		c.RLock()
-		producer := c.p
-		c.RUnlock()
+	c.logger.Debug("SENDING DATA => %s", redact.Payload(data))
 
-		c.logger.Debug("SENDING DATA => %s", string(data))
+	return c.produce(options.Topic, data, headers)
+}
 
-		if producer != nil {
-			var kheaders []kafka.Header
-			for k, v := range headers {
-				if val, ok := v.(string); ok {
-					kheaders = append(kheaders, kafka.Header{
-						Key:   k,
-						Value: []byte(val),
-					})
-				}
-			}
+// produce sends the data as it is, only the string headers are kept.
+func (c *Producer) produce(topic string, data []byte, headers map[string]interface{}) error {
+	c.RLock()
+	producer := c.p
+	c.RUnlock()
 
-			if err := producer.Produce(&kafka.Message{
-				TopicPartition: kafka.TopicPartition{Topic: &options.Topic, Partition: kafka.PartitionAny},
-				Value:          data,
-				Headers:        kheaders,
-			}, nil); err != nil {
-				c.logger.Error("Failed to produce data %s", err)
-			}
+	if producer == nil {
+		return dberrors.New("kafka", c.tag, "produce", dberrors.ErrNotConnected)
+	}
+
+	var kheaders []kafka.Header
+	for k, v := range headers {
+		if val, ok := v.(string); ok {
+			kheaders = append(kheaders, kafka.Header{
+				Key:   k,
+				Value: []byte(val),
+			})
 		}
 	}
 
+	if err := producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Value:          data,
+		Headers:        kheaders,
+	}, nil); err != nil {
+		return dberrors.New("kafka", c.tag, "produce", err)
+	}
+
+	metrics.KafkaProduced(c.tag, topic)
+	return nil
 }
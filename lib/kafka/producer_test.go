@@ -0,0 +1,31 @@
+package kafka
+
+import (
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+func TestProducerRotated(t *testing.T) {
+	t.Setenv("FLT_KAFKA_TEST_PASSWORD", "first")
+
+	lg := log.NewLib()
+	lg.Init("Test Kafka")
+
+	p := NewProducer(lg, database.KafkaProviderConfig{
+		Host:     "127.0.0.1:1",
+		Username: "client",
+		Password: "env:FLT_KAFKA_TEST_PASSWORD",
+	}, nil)
+	p.sasl = [2]string{"client", "first"}
+
+	if p.rotated() {
+		t.Fatal("rotated = true before the rotation")
+	}
+
+	t.Setenv("FLT_KAFKA_TEST_PASSWORD", "rotated")
+	if !p.rotated() {
+		t.Fatal("rotated = false after the rotation")
+	}
+}
@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/retry"
+	"strconv"
+	"time"
+)
+
+// retryPublisher produces the failed messages of a consumer with the producer of the
+// client, on the retry topic with the time they may be handled again, or on the dead
+// letter topic.
+type retryPublisher struct {
+	client  *Kafka
+	options database.KafkaOptions
+}
+
+func (p retryPublisher) Retry(msg database.Messages, headers map[string]interface{}, delay time.Duration) error {
+	retryTopic, _ := retry.Destinations(*p.options.Retry, p.options.Topic)
+	headers[database.HeaderRetryNotBefore] = strconv.FormatInt(time.Now().Add(delay).UnixMilli(), 10)
+	return p.produce("retry", retryTopic, msg, headers)
+}
+
+func (p retryPublisher) DeadLetter(msg database.Messages, headers map[string]interface{}) error {
+	_, deadLetter := retry.Destinations(*p.options.Retry, p.options.Topic)
+	delete(headers, database.HeaderRetryNotBefore)
+	if err := p.produce("dead letter", deadLetter, msg, headers); err != nil {
+		return err
+	}
+	p.client.log.Warning("[%s] message %s dead-lettered to %s", p.options.Topic, msg.ID(), deadLetter)
+	return nil
+}
+
+// produce sends the value the message was received with, the body of a message read
+// through the schema registry is the decoded one.
+func (p retryPublisher) produce(op, topic string, msg database.Messages, headers map[string]interface{}) error {
+	p.client.RLock()
+	producer := p.client.producer
+	p.client.RUnlock()
+
+	if producer == nil {
+		return dberrors.New("kafka", p.client.tag, op, dberrors.ErrNotConnected)
+	}
+
+	value := msg.Body()
+	if metadata := msg.Metadata().Kafka; metadata != nil && metadata.Value != nil {
+		value = metadata.Value
+	}
+	return producer.produce(topic, value, headers)
+}
+
+// partition is a partition paused until its next retried message may be handled.
+type partition struct {
+	topic     string
+	partition int32
+}
+
+// pause stops fetching the partition of a retried message until it may be handled,
+// the partition goes back to the message and the other partitions keep being
+// consumed. The offset stored when the message was polled is moved back too, the
+// message is not skipped when the partition is assigned to another member.
+func (c *Consumer) pause(consumer *kafka.Consumer, tp kafka.TopicPartition, notBefore time.Time, paused map[partition]time.Time) error {
+	partitions := []kafka.TopicPartition{tp}
+	if err := consumer.Pause(partitions); err != nil {
+		return err
+	}
+	if err := consumer.Seek(tp, 0); err != nil {
+		return err
+	}
+	if _, err := consumer.StoreOffsets(partitions); err != nil {
+		return err
+	}
+
+	paused[partition{topic: *tp.Topic, partition: tp.Partition}] = notBefore
+	return nil
+}
+
+// resume resumes the paused partitions whose retried message may be handled.
+func (c *Consumer) resume(consumer *kafka.Consumer, paused map[partition]time.Time) {
+	now := time.Now()
+	for p, notBefore := range paused {
+		if now.Before(notBefore) {
+			continue
+		}
+		delete(paused, p)
+
+		topic := p.topic
+		if err := consumer.Resume([]kafka.TopicPartition{{Topic: &topic, Partition: p.partition}}); err != nil {
+			// the partition was revoked meanwhile
+			c.logger.Debug("[%s] can not resume partition %d: %s", topic, p.partition, err)
+		}
+	}
+}
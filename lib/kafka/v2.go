@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"context"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	databasev2 "github.com/fajarardiyanto/flt-go-database/interfaces/v2"
+)
+
+type clientV2 struct {
+	*Kafka
+}
+
+// V2 returns the client with the context-first interface, Consumer and Producer
+// return the errors the v1 methods only log.
+func (c *Kafka) V2() databasev2.Kafka {
+	return clientV2{c}
+}
+
+func (v clientV2) Consumer(ctx context.Context, options database.KafkaOptions, callback database.ConsumerCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addConsumer(options, callback)
+}
+
+func (v clientV2) Handle(ctx context.Context, options database.KafkaOptions, handler database.ConsumerHandler) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addHandler(options, handler)
+}
+
+func (v clientV2) Producer(ctx context.Context, isReady database.ProducerIsReady) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addProducer(isReady)
+}
@@ -1,8 +1,11 @@
 package lib
 
 import (
+	"github.com/fajarardiyanto/flt-go-database/lib/kafka"
+	"github.com/fajarardiyanto/flt-go-database/lib/memory"
 	"github.com/fajarardiyanto/flt-go-database/lib/mongo"
 	"github.com/fajarardiyanto/flt-go-database/lib/rabbitmq"
+	"strings"
 	"sync"
 
 	database "github.com/fajarardiyanto/flt-go-database/interfaces"
@@ -13,12 +16,27 @@ import (
 )
 
 type Modules struct {
-	logging logger.Logger
+	logging    logger.Logger
+	registry   registry
+	lastErrors map[string]string
+	broker     *memory.Broker
 	sync.RWMutex
 }
 
 func NewLib() database.Database {
-	return &Modules{}
+	return &Modules{registry: newRegistry(), lastErrors: make(map[string]string)}
+}
+
+// NewMemoryLib returns the modules in memory mode, every Load returns the in-memory
+// implementation of the memory package and nothing connects to the network. The
+// RabbitMQ and Kafka clients exchange their messages through the broker, a new one
+// is created when it is nil.
+func NewMemoryLib(broker *memory.Broker) database.Database {
+	if broker == nil {
+		broker = memory.NewBroker()
+	}
+	return &Modules{registry: newRegistry(), lastErrors: make(map[string]string),
+		broker: broker}
 }
 
 func (m *Modules) Init(lo logger.Logger) {
@@ -26,21 +44,98 @@ func (m *Modules) Init(lo logger.Logger) {
 }
 
 func (m *Modules) LoadElasticSearch(tag string, config database.ElasticSearchProviderConfig) database.ElasticSearch {
-	return elasticsearch.NewElasticSearch(tag, m.logging, config)
+	var client database.ElasticSearch
+	if m.broker != nil {
+		client = memory.NewElasticSearch(config)
+	} else {
+		client = elasticsearch.NewElasticSearch(tag, m.logging, config)
+	}
+	m.Lock()
+	m.registry.elasticsearch[strings.ToLower(tag)] = client
+	m.Unlock()
+	return client
 }
 
 func (m *Modules) LoadSQLDatabase(config database.SQLConfig) database.SQL {
-	return sql.NewSQL(m.logging, config)
+	return m.LoadSQLDatabaseWithTag(DefaultTag, config)
+}
+
+func (m *Modules) LoadSQLDatabaseWithTag(tag string, config database.SQLConfig) database.SQL {
+	var client database.SQL
+	if m.broker != nil {
+		client = memory.NewSQL()
+	} else {
+		client = sql.NewSQLWithTag(tag, m.logging, config)
+	}
+	m.Lock()
+	m.registry.sql[strings.ToLower(tag)] = client
+	m.Unlock()
+	return client
 }
 
 func (m *Modules) LoadRedisDatabase(config database.RedisProviderConfig) database.Redis {
-	return redis.NewRedis(m.logging, config)
+	return m.LoadRedisDatabaseWithTag(DefaultTag, config)
+}
+
+func (m *Modules) LoadRedisDatabaseWithTag(tag string, config database.RedisProviderConfig) database.Redis {
+	var client database.Redis
+	if m.broker != nil {
+		client = memory.NewRedis()
+	} else {
+		client = redis.NewRedisWithTag(tag, m.logging, config)
+	}
+	m.Lock()
+	m.registry.redis[strings.ToLower(tag)] = client
+	m.Unlock()
+	return client
 }
 
 func (m *Modules) LoadMongoDatabase(config database.MongoProviderConfig) database.Mongo {
-	return mongo.NewMongo(m.logging, config)
+	return m.LoadMongoDatabaseWithTag(DefaultTag, config)
+}
+
+func (m *Modules) LoadMongoDatabaseWithTag(tag string, config database.MongoProviderConfig) database.Mongo {
+	var client database.Mongo
+	if m.broker != nil {
+		client = memory.NewMongo()
+	} else {
+		client = mongo.NewMongoWithTag(tag, m.logging, config)
+	}
+	m.Lock()
+	m.registry.mongo[strings.ToLower(tag)] = client
+	m.Unlock()
+	return client
 }
 
 func (c *Modules) LoadRabbitMQ(tag string, config database.RabbitMQProviderConfig) database.RabbitMQ {
-	return rabbitmq.NewRabbitMQ(tag, c.logging, config)
+	var client database.RabbitMQ
+	if c.broker != nil {
+		client = memory.NewRabbitMQ(c.broker)
+	} else {
+		client = rabbitmq.NewRabbitMQ(tag, c.logging, config)
+	}
+	c.Lock()
+	c.registry.rabbitmq[strings.ToLower(tag)] = client
+	c.Unlock()
+	return client
+}
+
+func (c *Modules) LoadKafka(tag string, config database.KafkaProviderConfig) database.Kafka {
+	var client database.Kafka
+	if c.broker != nil {
+		client = memory.NewKafka(c.broker)
+	} else {
+		client = kafka.NewKafka(tag, c.logging, config)
+	}
+	c.Lock()
+	c.registry.kafka[strings.ToLower(tag)] = client
+	c.Unlock()
+	return client
+}
+
+func (c *Modules) LoadKafkaByTag(tag string) (database.Kafka, bool) {
+	if client, ok := c.GetKafka(tag); ok || c.broker != nil {
+		return client, ok
+	}
+	return kafka.LoadKafka(tag)
 }
@@ -0,0 +1,151 @@
+package lib
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/config"
+)
+
+func (m *Modules) LoadFromFile(path string) error {
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	return m.LoadFromConfig(cfg)
+}
+
+func (m *Modules) LoadFromYAML(r io.Reader) error {
+	cfg, err := config.Load(r)
+	if err != nil {
+		return err
+	}
+	return m.LoadFromConfig(cfg)
+}
+
+// ConnectTimeout bounds the wait of LoadFromConfig for the brokers, which connect in
+// the background.
+const ConnectTimeout = 30 * time.Second
+
+// LoadFromConfig registers every enabled provider of the configuration under its
+// section name and connects it, the sections of a provider in the order of their
+// names. The Kafka clients start their producer, a RabbitMQ client with a dedicated
+// connection connects with its producer and consumers.
+func (m *Modules) LoadFromConfig(cfg *database.Config) error {
+	for _, tag := range tags(cfg.SQL) {
+		conf := cfg.SQL[tag]
+		if !conf.Enable {
+			continue
+		}
+		if err := m.LoadSQLDatabaseWithTag(tag, conf).LoadSQL(); err != nil {
+			return providerError("sql", tag, err)
+		}
+	}
+
+	for _, tag := range tags(cfg.Redis) {
+		conf := cfg.Redis[tag]
+		if !conf.Enable {
+			continue
+		}
+		if err := m.LoadRedisDatabaseWithTag(tag, conf).Init(); err != nil {
+			return providerError("redis", tag, err)
+		}
+	}
+
+	for _, tag := range tags(cfg.Mongo) {
+		conf := cfg.Mongo[tag]
+		if !conf.Enable {
+			continue
+		}
+		if err := m.LoadMongoDatabaseWithTag(tag, conf).Init(); err != nil {
+			return providerError("mongo", tag, err)
+		}
+	}
+
+	for _, tag := range tags(cfg.ElasticSearch) {
+		conf := cfg.ElasticSearch[tag]
+		if !conf.Enable {
+			continue
+		}
+		if err := m.LoadElasticSearch(tag, conf).ElasticSearch(); err != nil {
+			return providerError("elasticsearch", tag, err)
+		}
+	}
+
+	for _, tag := range tags(cfg.RabbitMQ) {
+		conf := cfg.RabbitMQ[tag]
+		if !conf.Enable {
+			continue
+		}
+		client := m.LoadRabbitMQ(tag, conf)
+		if conf.DedicatedConnection {
+			continue
+		}
+		if err := connected(client.Ping); err != nil {
+			return providerError("rabbitmq", tag, err)
+		}
+	}
+
+	for _, tag := range tags(cfg.Kafka) {
+		conf := cfg.Kafka[tag]
+		if !conf.Enable {
+			continue
+		}
+		client := m.LoadKafka(tag, conf)
+		client.Producer(nil)
+		if err := connected(client.Ping); err != nil {
+			return providerError("kafka", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// tags returns the names of the sections of a provider, sorted.
+func tags(sections interface{}) []string {
+	keys := reflect.ValueOf(sections).MapKeys()
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, key.String())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// connected pings the client until it answers or ConnectTimeout is over, the last
+// error is returned then.
+func connected(ping func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ConnectTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// providerError returns the error of a provider failing to connect, as it is when the
+// client already returned a *ProviderError.
+func providerError(provider, tag string, err error) error {
+	var perr *dberrors.ProviderError
+	if dberrors.As(err, &perr) {
+		return err
+	}
+	return dberrors.New(provider, tag, "connect", err)
+}
@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+func TestLoadFromYAMLBrokers(t *testing.T) {
+	logger := log.NewLib()
+	logger.Init("Test Loader")
+
+	db := NewMemoryLib(nil)
+	db.Init(logger)
+
+	err := db.LoadFromYAML(strings.NewReader(`
+rabbitmq:
+  orders:
+    enable: true
+  billing:
+    enable: true
+kafka:
+  events:
+    enable: true
+  disabled:
+    enable: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, client := range db.List() {
+		got = append(got, client.Provider+"/"+client.Tag)
+	}
+	want := "kafka/events rabbitmq/billing rabbitmq/orders"
+	if strings.Join(got, " ") != want {
+		t.Errorf("List() = %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,258 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// message is an entry of the log of a topic, the offset is its index.
+type message struct {
+	id           string
+	body         []byte
+	headers      map[string]interface{}
+	offset       int64
+	timestamp    time.Time
+	redeliveries int
+}
+
+// Broker routes the messages pushed by the RabbitMQ and Kafka fakes to their
+// consumers. Every topic keeps its log, each group of consumers reads it from the
+// start and the members of a group share its messages in round robin.
+type Broker struct {
+	topics  map[string]*topic
+	pending int
+	idle    *sync.Cond
+	sync.Mutex
+}
+
+type topic struct {
+	log    []message
+	groups map[string]*group
+}
+
+type group struct {
+	members []*member
+	next    int
+	offset  int
+}
+
+type member struct {
+	handle  func(message)
+	pending []message
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	sync.Mutex
+}
+
+func NewBroker() *Broker {
+	b := &Broker{topics: make(map[string]*topic)}
+	b.idle = sync.NewCond(&b.Mutex)
+	return b
+}
+
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{groups: make(map[string]*group)}
+		b.topics[name] = t
+	}
+	return t
+}
+
+func (b *Broker) publish(name, id string, body []byte, headers map[string]interface{}) {
+	b.Lock()
+	defer b.Unlock()
+
+	t := b.topic(name)
+	t.log = append(t.log, message{
+		id:        id,
+		body:      body,
+		headers:   headers,
+		offset:    int64(len(t.log)),
+		timestamp: time.Now(),
+	})
+	for _, g := range t.groups {
+		b.deliver(t, g)
+	}
+}
+
+// requeue delivers the message again to the next member of the group.
+func (b *Broker) requeue(name, groupID string, msg message) {
+	msg.redeliveries++
+
+	b.Lock()
+	b.pending++
+	b.Unlock()
+
+	b.redeliver(name, groupID, msg)
+}
+
+// retry delivers the message to the next member of the group once the delay is over,
+// Wait waits for it.
+func (b *Broker) retry(name, groupID string, msg message, delay time.Duration) {
+	b.Lock()
+	b.pending++
+	b.Unlock()
+
+	time.AfterFunc(delay, func() {
+		b.redeliver(name, groupID, msg)
+	})
+}
+
+// redeliver pushes a message already counted as pending to the next member of the
+// group, it is dropped when the group has no member left.
+func (b *Broker) redeliver(name, groupID string, msg message) {
+	b.Lock()
+	defer b.Unlock()
+
+	g, ok := b.topic(name).groups[groupID]
+	if !ok || len(g.members) == 0 {
+		b.pending--
+		b.idle.Broadcast()
+		return
+	}
+
+	m := g.members[g.next%len(g.members)]
+	g.next++
+	m.push(msg)
+}
+
+func (b *Broker) subscribe(name, groupID string, handle func(message)) *member {
+	m := &member{
+		handle: handle,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go m.run(b)
+
+	b.Lock()
+	defer b.Unlock()
+
+	t := b.topic(name)
+	g, ok := t.groups[groupID]
+	if !ok {
+		g = &group{}
+		t.groups[groupID] = g
+	}
+	g.members = append(g.members, m)
+	b.deliver(t, g)
+
+	return m
+}
+
+// unsubscribe removes the member from its group and waits for the message being
+// handled, the messages left in its mailbox are dropped.
+func (b *Broker) unsubscribe(m *member) {
+	b.Lock()
+	for _, t := range b.topics {
+		for _, g := range t.groups {
+			for i, val := range g.members {
+				if val == m {
+					g.members = append(g.members[:i], g.members[i+1:]...)
+				}
+			}
+		}
+	}
+	b.Unlock()
+
+	close(m.stop)
+	<-m.done
+
+	m.Lock()
+	dropped := len(m.pending)
+	m.pending = nil
+	m.Unlock()
+
+	b.Lock()
+	b.pending -= dropped
+	b.idle.Broadcast()
+	b.Unlock()
+}
+
+func (b *Broker) deliver(t *topic, g *group) {
+	for len(g.members) != 0 && g.offset < len(t.log) {
+		m := g.members[g.next%len(g.members)]
+		g.next++
+		b.pending++
+		m.push(t.log[g.offset])
+		g.offset++
+	}
+}
+
+func (b *Broker) handled() {
+	b.Lock()
+	b.pending--
+	if b.pending <= 0 {
+		b.idle.Broadcast()
+	}
+	b.Unlock()
+}
+
+// Wait blocks until every delivered message has been handled by its consumer or
+// the context is done.
+func (b *Broker) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.Lock()
+		for b.pending > 0 && ctx.Err() == nil {
+			b.idle.Wait()
+		}
+		b.Unlock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		b.Lock()
+		b.idle.Broadcast()
+		b.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (m *member) push(msg message) {
+	m.Lock()
+	m.pending = append(m.pending, msg)
+	m.Unlock()
+
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (m *member) run(b *Broker) {
+	defer close(m.done)
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-m.wake:
+		}
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			default:
+			}
+
+			m.Lock()
+			if len(m.pending) == 0 {
+				m.Unlock()
+				break
+			}
+			msg := m.pending[0]
+			m.pending = m.pending[1:]
+			m.Unlock()
+
+			m.handle(msg)
+			b.handled()
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+type document struct {
+	id     string
+	source interface{}
+	text   []string
+}
+
+// ElasticSearch implements interfaces.ElasticSearch with an in-memory document
+// store. A query matches the documents holding every of its terms in any field,
+// the hits are sorted in insertion order and After is the position of the last hit.
+type ElasticSearch struct {
+	config  database.ElasticSearchProviderConfig
+	indices map[string][]document
+	sync.RWMutex
+}
+
+func NewElasticSearch(config database.ElasticSearchProviderConfig) *ElasticSearch {
+	return &ElasticSearch{config: config, indices: make(map[string][]document)}
+}
+
+// Elastic returns nil, there is no client behind the store.
+func (c *ElasticSearch) Elastic() *elasticsearch.Client {
+	return nil
+}
+
+func (c *ElasticSearch) ElasticSearch() error {
+	return nil
+}
+
+func (c *ElasticSearch) CreateIndex(name string, mapping string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.indices[name]; ok {
+		return dberrors.WithStatus("elasticsearch", "", "create index", http.StatusBadRequest,
+			fmt.Errorf("resource_already_exists_exception: index [%s] already exists", name))
+	}
+	c.indices[name] = nil
+	return nil
+}
+
+func (c *ElasticSearch) Create(index string, id string, values interface{}) error {
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	var source interface{}
+	if err := json.Unmarshal(payload, &source); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, doc := range c.indices[index] {
+		if doc.id == id {
+			return dberrors.WithStatus("elasticsearch", "", "create", http.StatusConflict,
+				fmt.Errorf("version_conflict_engine_exception: [%s]: version conflict, document already exists", id))
+		}
+	}
+	c.indices[index] = append(c.indices[index], document{id: id, source: source, text: terms(source)})
+
+	return nil
+}
+
+func (c *ElasticSearch) Delete(id string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	docs := c.indices[c.config.IndexName]
+	for i, doc := range docs {
+		if doc.id == id {
+			c.indices[c.config.IndexName] = append(docs[:i:i], docs[i+1:]...)
+			return nil
+		}
+	}
+
+	return dberrors.WithStatus("elasticsearch", "", "delete", http.StatusNotFound,
+		fmt.Errorf("not_found: [%s]: document missing", id))
+}
+
+func (c *ElasticSearch) Search(config database.ElasticSearchOptions) (*database.SearchResultsElasticSearch, error) {
+	var result database.SearchResultsElasticSearch
+
+	if config.Size == 0 {
+		config.Size = 25
+	}
+
+	c.RLock()
+	docs, ok := c.indices[c.config.IndexName]
+	c.RUnlock()
+
+	if !ok {
+		return &result, dberrors.WithStatus("elasticsearch", "", "search", http.StatusNotFound,
+			fmt.Errorf("index_not_found_exception: no such index [%s]", c.config.IndexName))
+	}
+
+	query := terms(config.Query)
+	var matched []interface{}
+	for _, doc := range docs {
+		if contains(doc.text, query) {
+			matched = append(matched, doc.source)
+		}
+	}
+
+	if config.Sort == "desc" {
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	}
+
+	start := 0
+	if len(config.After) > 0 {
+		if after, err := strconv.Atoi(config.After[0]); err == nil {
+			start = after + 1
+		}
+	}
+
+	result.Total = len(matched)
+	for i := start; i < len(matched) && len(result.Hits) < config.Size; i++ {
+		result.Hits = append(result.Hits, matched[i])
+	}
+
+	if len(result.Hits) < 1 {
+		return nil, dberrors.New("elasticsearch", "", "search", dberrors.ErrNotFound)
+	}
+
+	return &result, nil
+}
+
+func (c *ElasticSearch) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *ElasticSearch) Close(ctx context.Context) error {
+	return nil
+}
+
+// terms returns the lower cased words of every string and number of the value.
+func terms(value interface{}) []string {
+	var words []string
+	switch val := value.(type) {
+	case string:
+		words = strings.FieldsFunc(strings.ToLower(val), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+	case float64, bool:
+		words = append(words, fmt.Sprint(val))
+	case []interface{}:
+		for _, item := range val {
+			words = append(words, terms(item)...)
+		}
+	case map[string]interface{}:
+		for _, item := range val {
+			words = append(words, terms(item)...)
+		}
+	}
+	return words
+}
+
+func contains(text, query []string) bool {
+	for _, term := range query {
+		found := false
+		for _, word := range text {
+			if word == term {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+var _ database.ElasticSearch = (*ElasticSearch)(nil)
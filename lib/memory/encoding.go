@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"context"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/compress"
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
+)
+
+// encode writes the body the same way the producers of the rabbitmq and kafka
+// packages do.
+func encode(enc database.Encoding, id string, body interface{}) ([]byte, error) {
+	return database.Encode(enc, id, body)
+}
+
+// decode builds the message handed to a consumer callback from the body decompressed
+// as the content-encoding header says, the codec is the one of the content-type header
+// and the context carries the span propagated in the headers.
+func decode(enc database.Encoding, m message, exchange, routingKey string, metadata database.MessageMetadata) (database.Messages, error) {
+	data, err := compress.Decompress(compress.Header(m.headers), m.body)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := database.NewMessage(data, exchange, routingKey, enc, database.MessageInfo{
+		ID:           m.id,
+		Headers:      m.headers,
+		Timestamp:    m.timestamp,
+		Redeliveries: m.redeliveries,
+		Metadata:     metadata,
+	})
+	msg.SetContext(tracing.Extract(context.Background(), m.headers))
+	return msg, nil
+}
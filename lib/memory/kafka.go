@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/compress"
+	"github.com/fajarardiyanto/flt-go-database/lib/middleware"
+	"github.com/fajarardiyanto/flt-go-database/lib/retry"
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
+	"github.com/fajarardiyanto/flt-go-utils/hash"
+)
+
+// Kafka implements interfaces.Kafka on top of a Broker. The topics have a single
+// partition and a new group reads them from the earliest offset.
+type Kafka struct {
+	broker    *Broker
+	ready     bool
+	consumers []*member
+	closed    bool
+	sync.RWMutex
+}
+
+func NewKafka(broker *Broker) *Kafka {
+	return &Kafka{broker: broker}
+}
+
+// Consumer ignores the options without topic, as the kafka package does after
+// logging the error.
+func (c *Kafka) Consumer(options database.KafkaOptions, callback database.ConsumerCallback) {
+	_ = c.addConsumer(options, callback)
+}
+
+// Handle retries the failed messages after the delay of the retry policy, without
+// going through a retry topic, the errors are dropped otherwise.
+func (c *Kafka) Handle(options database.KafkaOptions, handler database.ConsumerHandler) {
+	_ = c.addHandler(options, handler)
+}
+
+func (c *Kafka) addHandler(options database.KafkaOptions, handler database.ConsumerHandler) error {
+	if options.Retry != nil {
+		_, deadLetter := retry.Destinations(*options.Retry, options.Topic)
+		handler = retry.Handler(*options.Retry, retrier{
+			broker:     c.broker,
+			name:       "kafka/" + options.Topic,
+			groupID:    options.Group,
+			deadLetter: "kafka/" + deadLetter,
+		}, handler)
+	}
+	return c.addConsumer(options, func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+		_ = handler(msg, done)
+	})
+}
+
+func (c *Kafka) addConsumer(options database.KafkaOptions, callback database.ConsumerCallback) error {
+	if len(options.Topic) == 0 {
+		return dberrors.New("kafka", "", "consumer", errors.New("topic is required"))
+	}
+
+	callback = middleware.Chain(callback, options.Middlewares...)
+
+	m := c.broker.subscribe("kafka/"+options.Topic, options.Group, func(msg message) {
+		if callback == nil {
+			return
+		}
+		decoded, err := decode(options.Encoding, msg, options.Topic, options.Group, database.MessageMetadata{
+			Kafka: &database.KafkaMetadata{Topic: options.Topic, Offset: msg.offset},
+		})
+		if err != nil {
+			return
+		}
+		callback(decoded, database.ConsumerCallbackIsDone{
+			Done:       func() {},
+			EndRequest: func() {},
+		})
+	})
+
+	c.Lock()
+	c.consumers = append(c.consumers, m)
+	c.Unlock()
+
+	return nil
+}
+
+func (c *Kafka) Producer(isReady database.ProducerIsReady) {
+	c.Lock()
+	c.ready = true
+	c.Unlock()
+
+	if isReady != nil {
+		isReady()
+	}
+}
+
+// Push publishes the body encoded with the encoding of the options. As with the
+// kafka package a push with a callback blocks until the context is done.
+func (c *Kafka) Push(ctx context.Context, id string, options database.KafkaOptions, body interface{}, cb database.ConsumerCallback) error {
+	c.RLock()
+	ready, closed := c.ready, c.closed
+	c.RUnlock()
+
+	if !ready || closed {
+		return dberrors.New("kafka", "", "push", dberrors.ErrNotConnected)
+	}
+
+	if len(id) == 0 {
+		id = hash.CreateRandomId(10)
+	}
+
+	data, err := encode(options.Encoding, id, body)
+	if err != nil {
+		return err
+	}
+
+	data, contentEncoding, err := compress.Compress(options.Compression, options.CompressionThreshold, data)
+	if err != nil {
+		return err
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	headers := map[string]interface{}{
+		database.HeaderMessageID:   id,
+		database.HeaderContentType: database.ContentTypeOf(options.Encoding),
+	}
+	if len(contentEncoding) != 0 {
+		headers[database.HeaderContentEncoding] = contentEncoding
+	}
+	tracing.Inject(ctx, headers)
+	c.broker.publish("kafka/"+options.Topic, id, data, headers)
+
+	if cb != nil {
+		<-ctx.Done()
+	}
+
+	return nil
+}
+
+func (c *Kafka) Ping(ctx context.Context) error {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.closed {
+		return dberrors.New("kafka", "", "ping", dberrors.ErrNotConnected)
+	}
+	return nil
+}
+
+// Close stops the consumers once their current callback returns.
+func (c *Kafka) Close(ctx context.Context) error {
+	c.Lock()
+	c.closed = true
+	consumers := c.consumers
+	c.consumers = nil
+	c.Unlock()
+
+	for _, m := range consumers {
+		c.broker.unsubscribe(m)
+	}
+
+	return nil
+}
+
+var _ database.Kafka = (*Kafka)(nil)
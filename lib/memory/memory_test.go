@@ -0,0 +1,51 @@
+package memory_test
+
+import (
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/interfaces/interfacestest"
+	"github.com/fajarardiyanto/flt-go-database/lib/memory"
+)
+
+func TestRedis(t *testing.T) {
+	interfacestest.RunRedisSuite(t, func(t *testing.T) database.Redis {
+		return memory.NewRedis()
+	})
+}
+
+func TestElasticSearch(t *testing.T) {
+	interfacestest.RunElasticSearchSuite(t, func(t *testing.T, index string) database.ElasticSearch {
+		return memory.NewElasticSearch(database.ElasticSearchProviderConfig{IndexName: index})
+	})
+}
+
+func TestMongo(t *testing.T) {
+	interfacestest.RunMongoSuite(t, func(t *testing.T) database.Mongo {
+		return memory.NewMongo()
+	})
+}
+
+func TestSQL(t *testing.T) {
+	interfacestest.RunSQLSuite(t, func(t *testing.T) database.SQL {
+		client := memory.NewSQL()
+		if err := client.LoadSQL(); err != nil {
+			t.Fatalf("LoadSQL: %v", err)
+		}
+		return client
+	})
+}
+
+func TestRabbitMQ(t *testing.T) {
+	broker := memory.NewBroker()
+	interfacestest.RunRabbitMQSuite(t, func(t *testing.T) database.RabbitMQ {
+		return memory.NewRabbitMQ(broker)
+	})
+}
+
+func TestKafka(t *testing.T) {
+	broker := memory.NewBroker()
+	interfacestest.RunKafkaSuite(t, func(t *testing.T) database.Kafka {
+		return memory.NewKafka(broker)
+	})
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Mongo implements interfaces.Mongo with in-memory collections filled by Insert.
+// The filters only match top level fields by equality and the find options are
+// ignored.
+type Mongo struct {
+	collections map[string][]bson.M
+	sync.RWMutex
+}
+
+func NewMongo() *Mongo {
+	return &Mongo{collections: make(map[string][]bson.M)}
+}
+
+func (s *Mongo) Init() error {
+	return nil
+}
+
+// SetDatabase returns nil, the driver database can not be faked.
+func (s *Mongo) SetDatabase(db string) *mongo.Database {
+	return nil
+}
+
+func (s *Mongo) Insert(db, table string, docs ...bson.M) {
+	s.Lock()
+	defer s.Unlock()
+
+	key := db + "." + table
+	s.collections[key] = append(s.collections[key], docs...)
+}
+
+func (s *Mongo) LoadPostChannel(ctx context.Context, db, table string, filter bson.M, res chan<- []bson.M, opt ...*options.FindOptions) {
+	res <- s.find(db, table, filter)
+}
+
+func (s *Mongo) find(db, table string, filter bson.M) []bson.M {
+	s.RLock()
+	docs := s.collections[db+"."+table]
+	s.RUnlock()
+
+	results := make([]bson.M, 0)
+	for _, doc := range docs {
+		if match(doc, filter) {
+			results = append(results, doc)
+		}
+	}
+	return results
+}
+
+func (s *Mongo) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Mongo) Close(ctx context.Context) error {
+	return nil
+}
+
+func match(doc, filter bson.M) bool {
+	for key, val := range filter {
+		if !reflect.DeepEqual(doc[key], val) {
+			return false
+		}
+	}
+	return true
+}
+
+var _ database.Mongo = (*Mongo)(nil)
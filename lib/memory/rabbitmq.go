@@ -0,0 +1,205 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/compress"
+	"github.com/fajarardiyanto/flt-go-database/lib/middleware"
+	"github.com/fajarardiyanto/flt-go-database/lib/retry"
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
+	"github.com/fajarardiyanto/flt-go-utils/hash"
+)
+
+// RabbitMQ implements interfaces.RabbitMQ on top of a Broker. Push publishes on the
+// queue named by the key, a consumer reads the queue named by its exchange, or by
+// the hash of the exchange and routing key when the routing key is set, like the
+// rabbitmq package does. The callbacks of a consumer run one after the other, as
+// with Sequential, whatever its Workers.
+type RabbitMQ struct {
+	broker    *Broker
+	producer  *database.RabbitMQOptions
+	consumers []*member
+	closed    bool
+	sync.RWMutex
+}
+
+func NewRabbitMQ(broker *Broker) *RabbitMQ {
+	return &RabbitMQ{broker: broker}
+}
+
+func (c *RabbitMQ) Producer(options database.RabbitMQOptions) {
+	if len(options.ExchangeType) == 0 {
+		options.ExchangeType = "direct"
+	}
+
+	c.Lock()
+	c.producer = &options
+	c.Unlock()
+}
+
+// Consumer ignores the options without exchange, as the rabbitmq package does after
+// logging the error.
+func (c *RabbitMQ) Consumer(options database.RabbitMQOptions, callback database.ConsumerCallback) {
+	_ = c.addConsumer(options, callback)
+}
+
+// Handle retries the failed messages after the delay of the retry policy, without
+// going through a retry queue.
+func (c *RabbitMQ) Handle(options database.RabbitMQOptions, handler database.ConsumerHandler) {
+	_ = c.addHandler(options, handler)
+}
+
+func (c *RabbitMQ) addHandler(options database.RabbitMQOptions, handler database.ConsumerHandler) error {
+	if options.Retry != nil {
+		options.ManualAck = true
+		_, deadLetter := retry.Destinations(*options.Retry, queueOf(options))
+		handler = retry.Handler(*options.Retry, retrier{
+			broker:     c.broker,
+			name:       "amqp/" + queueOf(options),
+			deadLetter: "amqp/" + deadLetter,
+		}, handler)
+	}
+	return c.addConsumer(options, database.AckAfter(handler, options.RequeueOnError))
+}
+
+func (c *RabbitMQ) addConsumer(options database.RabbitMQOptions, callback database.ConsumerCallback) error {
+	if len(options.Exchange) == 0 {
+		return dberrors.New("rabbitmq", "", "consumer", errors.New("exchange is required"))
+	}
+
+	callback = middleware.Chain(callback, options.Middlewares...)
+
+	queue := queueOf(options)
+
+	m := c.broker.subscribe("amqp/"+queue, "", func(msg message) {
+		if callback == nil {
+			return
+		}
+		decoded, err := decode(options.Encoding, msg, options.Exchange, options.RoutingKey, database.MessageMetadata{
+			AMQP: &database.AMQPMetadata{
+				DeliveryTag: uint64(msg.offset) + 1,
+				RoutingKey:  queue,
+				Redelivered: msg.redeliveries != 0,
+			},
+		})
+		if err != nil {
+			return
+		}
+
+		done := database.ConsumerCallbackIsDone{
+			Done:       func() {},
+			EndRequest: func() {},
+		}
+		if options.ManualAck {
+			done.Acknowledger = c.acknowledger("amqp/"+queue, msg)
+		}
+		callback(decoded, done)
+	})
+
+	c.Lock()
+	c.consumers = append(c.consumers, m)
+	c.Unlock()
+
+	return nil
+}
+
+// queueOf returns the queue of a consumer, the exchange or the hash of the exchange
+// and routing key when the routing key is set.
+func queueOf(options database.RabbitMQOptions) string {
+	if len(options.RoutingKey) == 0 {
+		return options.Exchange
+	}
+	return hash.CreateSmallHash(10, options.Exchange, options.RoutingKey)
+}
+
+// acknowledger requeues the message on a Nack with requeue, Ack and Reject only
+// settle it.
+func (c *RabbitMQ) acknowledger(name string, msg message) database.Acknowledger {
+	settle := func() error { return nil }
+	return database.NewDelivery(settle, func(requeue bool) error {
+		if requeue {
+			c.broker.requeue(name, "", msg)
+		}
+		return nil
+	}, settle)
+}
+
+// Push publishes the body encoded with the encoding of the producer. As with the
+// rabbitmq package a push with a callback blocks until the context is done.
+func (c *RabbitMQ) Push(ctx context.Context, id, key string, body interface{}, cb database.ConsumerCallback) error {
+	c.RLock()
+	producer, closed := c.producer, c.closed
+	c.RUnlock()
+
+	if producer == nil || closed {
+		return dberrors.New("rabbitmq", "", "push", dberrors.ErrNotConnected)
+	}
+
+	if len(key) == 0 {
+		return nil
+	}
+
+	if len(id) == 0 {
+		id = hash.CreateRandomId(10)
+	}
+
+	data, err := encode(producer.Encoding, id, body)
+	if err != nil {
+		return err
+	}
+
+	data, contentEncoding, err := compress.Compress(producer.Compression, producer.CompressionThreshold, data)
+	if err != nil {
+		return err
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	headers := map[string]interface{}{
+		database.HeaderContentType: database.ContentTypeOf(producer.Encoding),
+	}
+	if len(contentEncoding) != 0 {
+		headers[database.HeaderContentEncoding] = contentEncoding
+	}
+	tracing.Inject(ctx, headers)
+	c.broker.publish("amqp/"+key, id, data, headers)
+
+	if cb != nil {
+		<-ctx.Done()
+	}
+
+	return nil
+}
+
+func (c *RabbitMQ) Ping(ctx context.Context) error {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.closed {
+		return dberrors.New("rabbitmq", "", "ping", dberrors.ErrNotConnected)
+	}
+	return nil
+}
+
+// Close stops the consumers once their current callback returns.
+func (c *RabbitMQ) Close(ctx context.Context) error {
+	c.Lock()
+	c.closed = true
+	consumers := c.consumers
+	c.consumers = nil
+	c.Unlock()
+
+	for _, m := range consumers {
+		c.broker.unsubscribe(m)
+	}
+
+	return nil
+}
+
+var _ database.RabbitMQ = (*RabbitMQ)(nil)
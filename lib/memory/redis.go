@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"sync"
+	"time"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/go-redis/redis/v8"
+)
+
+type entry struct {
+	value   string
+	expires time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// Redis is a map backed implementation of interfaces.Redis, the keys expire with
+// their TTL.
+type Redis struct {
+	items map[string]entry
+	now   func() time.Time
+	sync.RWMutex
+}
+
+func NewRedis() *Redis {
+	return &Redis{items: make(map[string]entry), now: time.Now}
+}
+
+func (s *Redis) Init() error {
+	return nil
+}
+
+func (s *Redis) GetPool() *redis.PoolStats {
+	return &redis.PoolStats{}
+}
+
+func (s *Redis) Set(ctx context.Context, key string, val interface{}, ttl time.Duration) error {
+	value, err := format(val)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	item := entry{value: value}
+	switch {
+	case ttl == redis.KeepTTL:
+		if old, ok := s.items[key]; ok && !old.expired(s.now()) {
+			item.expires = old.expires
+		}
+	case ttl > 0:
+		item.expires = s.now().Add(ttl)
+	}
+	s.items[key] = item
+
+	return nil
+}
+
+func (s *Redis) Get(ctx context.Context, key string) (string, error) {
+	s.RLock()
+	item, ok := s.items[key]
+	s.RUnlock()
+
+	if !ok || item.expired(s.now()) {
+		return "", dberrors.New("redis", "", "get", fmt.Errorf("%w: key %s", dberrors.ErrNotFound, key))
+	}
+
+	return item.value, nil
+}
+
+// TTL returns the remaining time to live of the key, -1 when it has no expiration.
+func (s *Redis) TTL(key string) (time.Duration, bool) {
+	s.RLock()
+	item, ok := s.items[key]
+	s.RUnlock()
+
+	now := s.now()
+	if !ok || item.expired(now) {
+		return 0, false
+	}
+	if item.expires.IsZero() {
+		return -1, true
+	}
+	return item.expires.Sub(now), true
+}
+
+func (s *Redis) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Redis) Close(ctx context.Context) error {
+	return nil
+}
+
+// format converts the value the same way go-redis writes arguments.
+func format(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	return "", fmt.Errorf("redis: can't marshal %T (implement encoding.BinaryMarshaler)", val)
+}
+
+var _ interfaces.Redis = (*Redis)(nil)
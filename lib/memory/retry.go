@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+// retrier delivers the failed messages of a consumer again to its group once the
+// delay is over, or publishes them on the dead letter topic of the broker.
+type retrier struct {
+	broker     *Broker
+	name       string
+	groupID    string
+	deadLetter string
+}
+
+func (r retrier) Retry(msg database.Messages, headers map[string]interface{}, delay time.Duration) error {
+	r.broker.retry(r.name, r.groupID, message{
+		id:        msg.ID(),
+		body:      msg.Body(),
+		headers:   headers,
+		timestamp: time.Now(),
+	}, delay)
+	return nil
+}
+
+func (r retrier) DeadLetter(msg database.Messages, headers map[string]interface{}) error {
+	r.broker.publish(r.deadLetter, msg.ID(), msg.Body(), headers)
+	return nil
+}
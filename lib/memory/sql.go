@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// SQL implements interfaces.SQL with a dry run session, the statements are built
+// in Statement.SQL and never executed.
+type SQL struct {
+	db *gorm.DB
+}
+
+func NewSQL() *SQL {
+	return &SQL{}
+}
+
+func (c *SQL) Orm() *gorm.DB {
+	return c.db
+}
+
+func (c *SQL) MySQL() error {
+	return c.LoadSQL()
+}
+
+func (c *SQL) LoadSQL() error {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "memory:memory@tcp(127.0.0.1:3306)/memory",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		return err
+	}
+
+	c.db = db
+	return nil
+}
+
+func (c *SQL) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *SQL) Close(ctx context.Context) error {
+	return nil
+}
+
+var _ database.SQL = (*SQL)(nil)
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	databasev2 "github.com/fajarardiyanto/flt-go-database/interfaces/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoV2 struct {
+	*Mongo
+}
+
+// V2 returns the fake with the context-first interface, Find reads the in-memory
+// collections.
+func (s *Mongo) V2() databasev2.Mongo {
+	return mongoV2{s}
+}
+
+func (v mongoV2) Connect(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (v mongoV2) Find(ctx context.Context, db, table string, filter bson.M, opt ...*options.FindOptions) ([]bson.M, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return v.find(db, table, filter), nil
+}
+
+type rabbitMQV2 struct {
+	*RabbitMQ
+}
+
+// V2 returns the fake with the context-first interface.
+func (c *RabbitMQ) V2() databasev2.RabbitMQ {
+	return rabbitMQV2{c}
+}
+
+func (v rabbitMQV2) Consumer(ctx context.Context, options database.RabbitMQOptions, callback database.ConsumerCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addConsumer(options, callback)
+}
+
+func (v rabbitMQV2) Handle(ctx context.Context, options database.RabbitMQOptions, handler database.ConsumerHandler) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addHandler(options, handler)
+}
+
+func (v rabbitMQV2) Producer(ctx context.Context, options database.RabbitMQOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	v.RabbitMQ.Producer(options)
+	return nil
+}
+
+type kafkaV2 struct {
+	*Kafka
+}
+
+// V2 returns the fake with the context-first interface.
+func (c *Kafka) V2() databasev2.Kafka {
+	return kafkaV2{c}
+}
+
+func (v kafkaV2) Consumer(ctx context.Context, options database.KafkaOptions, callback database.ConsumerCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addConsumer(options, callback)
+}
+
+func (v kafkaV2) Handle(ctx context.Context, options database.KafkaOptions, handler database.ConsumerHandler) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addHandler(options, handler)
+}
+
+func (v kafkaV2) Producer(ctx context.Context, isReady database.ProducerIsReady) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	v.Kafka.Producer(isReady)
+	return nil
+}
@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"database/sql"
+
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EnableMetrics registers the collectors of every provider on the registry, the
+// series are labelled with the tag of the client.
+func (m *Modules) EnableMetrics(reg prometheus.Registerer) error {
+	if err := metrics.Register(reg); err != nil {
+		return err
+	}
+
+	return reg.Register(metrics.NewStatsCollector(m.sqlStats, m.redisStats))
+}
+
+func (m *Modules) sqlStats() map[string]sql.DBStats {
+	m.RLock()
+	defer m.RUnlock()
+
+	stats := make(map[string]sql.DBStats)
+	for tag, client := range m.registry.sql {
+		if orm := client.Orm(); orm != nil {
+			if db, err := orm.DB(); err == nil {
+				stats[tag] = db.Stats()
+			}
+		}
+	}
+	return stats
+}
+
+func (m *Modules) redisStats() map[string]*redis.PoolStats {
+	m.RLock()
+	defer m.RUnlock()
+
+	stats := make(map[string]*redis.PoolStats)
+	for tag, client := range m.registry.redis {
+		stats[tag] = client.GetPool()
+	}
+	return stats
+}
@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace prefixes the name of every series exported by the library.
+const Namespace = "database"
+
+type Metrics struct {
+	esRequests          *prometheus.CounterVec
+	esLatency           *prometheus.HistogramVec
+	mongoPoolEvents     *prometheus.CounterVec
+	mongoCheckedOut     *prometheus.GaugeVec
+	rabbitmqPublished   *prometheus.CounterVec
+	rabbitmqConfirmed   *prometheus.CounterVec
+	rabbitmqNacked      *prometheus.CounterVec
+	rabbitmqConsumed    *prometheus.CounterVec
+	kafkaProduced       *prometheus.CounterVec
+	kafkaDeliveryFailed *prometheus.CounterVec
+	kafkaConsumed       *prometheus.CounterVec
+	kafkaLag            *prometheus.GaugeVec
+	consumerHandled     *prometheus.CounterVec
+	consumerDuration    *prometheus.HistogramVec
+}
+
+var (
+	current *Metrics
+	mutex   sync.RWMutex
+)
+
+func New() *Metrics {
+	return &Metrics{
+		esRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "elasticsearch", Name: "requests_total",
+			Help: "Number of elasticsearch requests by method and status code.",
+		}, []string{"tag", "method", "code"}),
+		esLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace, Subsystem: "elasticsearch", Name: "request_duration_seconds",
+			Help:    "Latency of elasticsearch requests.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"tag", "method"}),
+		mongoPoolEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "mongo", Name: "pool_events_total",
+			Help: "Number of mongo connection pool events by type.",
+		}, []string{"tag", "event"}),
+		mongoCheckedOut: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace, Subsystem: "mongo", Name: "pool_checked_out_connections",
+			Help: "Number of mongo connections currently checked out of the pool.",
+		}, []string{"tag"}),
+		rabbitmqPublished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "rabbitmq", Name: "published_total",
+			Help: "Number of messages published to rabbitmq.",
+		}, []string{"tag", "queue"}),
+		rabbitmqConfirmed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "rabbitmq", Name: "confirmed_total",
+			Help: "Number of publishings acknowledged by rabbitmq.",
+		}, []string{"tag"}),
+		rabbitmqNacked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "rabbitmq", Name: "nacked_total",
+			Help: "Number of publishings negatively acknowledged by rabbitmq.",
+		}, []string{"tag"}),
+		rabbitmqConsumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "rabbitmq", Name: "consumed_total",
+			Help: "Number of messages consumed from rabbitmq.",
+		}, []string{"tag", "exchange"}),
+		kafkaProduced: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "kafka", Name: "produced_total",
+			Help: "Number of messages produced to kafka.",
+		}, []string{"tag", "topic"}),
+		kafkaDeliveryFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "kafka", Name: "delivery_failed_total",
+			Help: "Number of messages kafka failed to deliver.",
+		}, []string{"tag", "topic"}),
+		kafkaConsumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "kafka", Name: "consumed_total",
+			Help: "Number of messages consumed from kafka.",
+		}, []string{"tag", "topic"}),
+		kafkaLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace, Subsystem: "kafka", Name: "consumer_lag",
+			Help: "Number of messages behind the high watermark of the partition.",
+		}, []string{"tag", "topic", "partition"}),
+		consumerHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "consumer", Name: "handled_total",
+			Help: "Number of messages handled by the consumer callbacks by outcome.",
+		}, []string{"tag", "destination", "outcome"}),
+		consumerDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace, Subsystem: "consumer", Name: "handle_duration_seconds",
+			Help:    "Time spent by the consumer callbacks on a message.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"tag", "destination"}),
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.esRequests, m.esLatency,
+		m.mongoPoolEvents, m.mongoCheckedOut,
+		m.rabbitmqPublished, m.rabbitmqConfirmed, m.rabbitmqNacked, m.rabbitmqConsumed,
+		m.kafkaProduced, m.kafkaDeliveryFailed, m.kafkaConsumed, m.kafkaLag,
+		m.consumerHandled, m.consumerDuration,
+	}
+}
+
+// Register registers the collectors on the registry and enables the recording of
+// every provider. Nothing is recorded until Register is called.
+func Register(reg prometheus.Registerer) error {
+	m := New()
+	for _, collector := range m.collectors() {
+		if err := reg.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	mutex.Lock()
+	current = m
+	mutex.Unlock()
+
+	return nil
+}
+
+func load() *Metrics {
+	mutex.RLock()
+	m := current
+	mutex.RUnlock()
+	return m
+}
+
+func ElasticSearchRequest(tag, method string, code int, duration time.Duration) {
+	if m := load(); m != nil {
+		m.esRequests.WithLabelValues(tag, method, strconv.Itoa(code)).Inc()
+		m.esLatency.WithLabelValues(tag, method).Observe(duration.Seconds())
+	}
+}
+
+func MongoPoolEvent(tag, event string) {
+	m := load()
+	if m == nil {
+		return
+	}
+
+	m.mongoPoolEvents.WithLabelValues(tag, event).Inc()
+	switch event {
+	case "ConnectionCheckedOut":
+		m.mongoCheckedOut.WithLabelValues(tag).Inc()
+	case "ConnectionCheckedIn":
+		m.mongoCheckedOut.WithLabelValues(tag).Dec()
+	}
+}
+
+func RabbitMQPublished(tag, queue string) {
+	if m := load(); m != nil {
+		m.rabbitmqPublished.WithLabelValues(tag, queue).Inc()
+	}
+}
+
+func RabbitMQConfirmed(tag string, ack bool) {
+	m := load()
+	if m == nil {
+		return
+	}
+
+	if ack {
+		m.rabbitmqConfirmed.WithLabelValues(tag).Inc()
+	} else {
+		m.rabbitmqNacked.WithLabelValues(tag).Inc()
+	}
+}
+
+func RabbitMQConsumed(tag, exchange string) {
+	if m := load(); m != nil {
+		m.rabbitmqConsumed.WithLabelValues(tag, exchange).Inc()
+	}
+}
+
+func KafkaProduced(tag, topic string) {
+	if m := load(); m != nil {
+		m.kafkaProduced.WithLabelValues(tag, topic).Inc()
+	}
+}
+
+func KafkaDeliveryFailed(tag, topic string) {
+	if m := load(); m != nil {
+		m.kafkaDeliveryFailed.WithLabelValues(tag, topic).Inc()
+	}
+}
+
+func KafkaConsumed(tag, topic string, partition int32, lag int64) {
+	m := load()
+	if m == nil {
+		return
+	}
+
+	m.kafkaConsumed.WithLabelValues(tag, topic).Inc()
+	if lag >= 0 {
+		m.kafkaLag.WithLabelValues(tag, topic, strconv.Itoa(int(partition))).Set(float64(lag))
+	}
+}
+
+func ConsumerHandled(tag, destination, outcome string, duration time.Duration) {
+	if m := load(); m != nil {
+		m.consumerHandled.WithLabelValues(tag, destination, outcome).Inc()
+		m.consumerDuration.WithLabelValues(tag, destination).Observe(duration.Seconds())
+	}
+}
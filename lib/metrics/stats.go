@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type SQLStats func() map[string]sql.DBStats
+type RedisStats func() map[string]*redis.PoolStats
+
+// StatsCollector reads the pool statistics of the SQL and Redis clients at scrape
+// time.
+type StatsCollector struct {
+	sql   SQLStats
+	redis RedisStats
+
+	sqlMaxOpen           *prometheus.Desc
+	sqlOpen              *prometheus.Desc
+	sqlInUse             *prometheus.Desc
+	sqlIdle              *prometheus.Desc
+	sqlWaitCount         *prometheus.Desc
+	sqlWaitDuration      *prometheus.Desc
+	sqlMaxIdleClosed     *prometheus.Desc
+	sqlMaxIdleTimeClosed *prometheus.Desc
+	sqlMaxLifetimeClosed *prometheus.Desc
+
+	redisHits       *prometheus.Desc
+	redisMisses     *prometheus.Desc
+	redisTimeouts   *prometheus.Desc
+	redisTotalConns *prometheus.Desc
+	redisIdleConns  *prometheus.Desc
+	redisStaleConns *prometheus.Desc
+}
+
+func NewStatsCollector(sqlStats SQLStats, redisStats RedisStats) *StatsCollector {
+	desc := func(subsystem, name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(Namespace, subsystem, name), help, []string{"tag"}, nil)
+	}
+
+	return &StatsCollector{
+		sql:   sqlStats,
+		redis: redisStats,
+
+		sqlMaxOpen:           desc("sql", "max_open_connections", "Maximum number of open connections to the database."),
+		sqlOpen:              desc("sql", "open_connections", "Number of established connections, in use and idle."),
+		sqlInUse:             desc("sql", "in_use_connections", "Number of connections currently in use."),
+		sqlIdle:              desc("sql", "idle_connections", "Number of idle connections."),
+		sqlWaitCount:         desc("sql", "wait_count_total", "Number of connections waited for."),
+		sqlWaitDuration:      desc("sql", "wait_duration_seconds_total", "Time blocked waiting for a new connection."),
+		sqlMaxIdleClosed:     desc("sql", "max_idle_closed_total", "Number of connections closed due to SetMaxIdleConns."),
+		sqlMaxIdleTimeClosed: desc("sql", "max_idle_time_closed_total", "Number of connections closed due to SetConnMaxIdleTime."),
+		sqlMaxLifetimeClosed: desc("sql", "max_lifetime_closed_total", "Number of connections closed due to SetConnMaxLifetime."),
+
+		redisHits:       desc("redis", "pool_hits_total", "Number of times a free connection was found in the pool."),
+		redisMisses:     desc("redis", "pool_misses_total", "Number of times a free connection was not found in the pool."),
+		redisTimeouts:   desc("redis", "pool_timeouts_total", "Number of times a wait timeout occurred."),
+		redisTotalConns: desc("redis", "pool_total_connections", "Number of total connections in the pool."),
+		redisIdleConns:  desc("redis", "pool_idle_connections", "Number of idle connections in the pool."),
+		redisStaleConns: desc("redis", "pool_stale_connections_total", "Number of stale connections removed from the pool."),
+	}
+}
+
+func (c *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.sqlMaxOpen, c.sqlOpen, c.sqlInUse, c.sqlIdle, c.sqlWaitCount, c.sqlWaitDuration,
+		c.sqlMaxIdleClosed, c.sqlMaxIdleTimeClosed, c.sqlMaxLifetimeClosed,
+		c.redisHits, c.redisMisses, c.redisTimeouts, c.redisTotalConns, c.redisIdleConns, c.redisStaleConns,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.sql != nil {
+		for tag, stats := range c.sql() {
+			ch <- prometheus.MustNewConstMetric(c.sqlMaxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections), tag)
+			ch <- prometheus.MustNewConstMetric(c.sqlOpen, prometheus.GaugeValue, float64(stats.OpenConnections), tag)
+			ch <- prometheus.MustNewConstMetric(c.sqlInUse, prometheus.GaugeValue, float64(stats.InUse), tag)
+			ch <- prometheus.MustNewConstMetric(c.sqlIdle, prometheus.GaugeValue, float64(stats.Idle), tag)
+			ch <- prometheus.MustNewConstMetric(c.sqlWaitCount, prometheus.CounterValue, float64(stats.WaitCount), tag)
+			ch <- prometheus.MustNewConstMetric(c.sqlWaitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), tag)
+			ch <- prometheus.MustNewConstMetric(c.sqlMaxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed), tag)
+			ch <- prometheus.MustNewConstMetric(c.sqlMaxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), tag)
+			ch <- prometheus.MustNewConstMetric(c.sqlMaxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), tag)
+		}
+	}
+
+	if c.redis != nil {
+		for tag, stats := range c.redis() {
+			if stats == nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.redisHits, prometheus.CounterValue, float64(stats.Hits), tag)
+			ch <- prometheus.MustNewConstMetric(c.redisMisses, prometheus.CounterValue, float64(stats.Misses), tag)
+			ch <- prometheus.MustNewConstMetric(c.redisTimeouts, prometheus.CounterValue, float64(stats.Timeouts), tag)
+			ch <- prometheus.MustNewConstMetric(c.redisTotalConns, prometheus.GaugeValue, float64(stats.TotalConns), tag)
+			ch <- prometheus.MustNewConstMetric(c.redisIdleConns, prometheus.GaugeValue, float64(stats.IdleConns), tag)
+			ch <- prometheus.MustNewConstMetric(c.redisStaleConns, prometheus.CounterValue, float64(stats.StaleConns), tag)
+		}
+	}
+}
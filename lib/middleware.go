@@ -0,0 +1,13 @@
+package lib
+
+import (
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/middleware"
+)
+
+// UseConsumerMiddleware wraps the callback of every RabbitMQ and Kafka consumer of the
+// process created after with the middlewares, see the middleware package for the
+// built-ins.
+func UseConsumerMiddleware(middlewares ...database.ConsumerMiddleware) {
+	middleware.Use(middlewares...)
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
+	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
+)
+
+// The outcomes recorded by Metrics.
+const (
+	OutcomeOK      = "ok"
+	OutcomeTimeout = "timeout"
+	OutcomePanic   = "panic"
+)
+
+var (
+	global []database.ConsumerMiddleware
+	mutex  sync.RWMutex
+)
+
+// Use registers middlewares wrapping the callback of every consumer created after.
+func Use(middlewares ...database.ConsumerMiddleware) {
+	mutex.Lock()
+	global = append(global, middlewares...)
+	mutex.Unlock()
+}
+
+// Chain wraps the callback with the global middlewares, then the ones of the consumer,
+// the first one is the outermost. A nil callback stays nil.
+func Chain(callback database.ConsumerCallback, middlewares ...database.ConsumerMiddleware) database.ConsumerCallback {
+	if callback == nil {
+		return nil
+	}
+
+	mutex.RLock()
+	chain := make([]database.ConsumerMiddleware, 0, len(global)+len(middlewares))
+	chain = append(chain, global...)
+	mutex.RUnlock()
+	chain = append(chain, middlewares...)
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i] != nil {
+			callback = chain[i](callback)
+		}
+	}
+	return callback
+}
+
+// Recover logs the panic of a callback with its stack instead of crashing the
+// service, then rejects the delivery, a no-op unless the consumer has ManualAck. The
+// retry policy of a handler turns its panic into a failure first, retried then
+// dead-lettered.
+func Recover(lg logger.Logger) database.ConsumerMiddleware {
+	return func(next database.ConsumerCallback) database.ConsumerCallback {
+		return func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			defer func() {
+				if r := recover(); r != nil {
+					lg.Error("[%s] panic handling message %s: %v\n%s", msg.Exchange(), msg.ID(), r, debug.Stack())
+					_ = done.Reject()
+				}
+			}()
+			next(msg, done)
+		}
+	}
+}
+
+// Logging logs the messages received and the time spent on them.
+func Logging(lg logger.Logger) database.ConsumerMiddleware {
+	return func(next database.ConsumerCallback) database.ConsumerCallback {
+		return func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			start := time.Now()
+			lg.Debug("[%s] message %s received", msg.Exchange(), msg.ID())
+			next(msg, done)
+			lg.Debug("[%s] message %s handled in %s", msg.Exchange(), msg.ID(), time.Since(start))
+		}
+	}
+}
+
+// Timeout cancels the context of the message after the timeout, the callback is
+// expected to give up once the context is done.
+func Timeout(timeout time.Duration) database.ConsumerMiddleware {
+	return func(next database.ConsumerCallback) database.ConsumerCallback {
+		return func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			parent := msg.Context()
+			if parent == nil {
+				parent = context.Background()
+			}
+
+			ctx, cancel := context.WithTimeout(parent, timeout)
+			defer cancel()
+
+			msg.SetContext(ctx)
+			next(msg, done)
+		}
+	}
+}
+
+// Metrics records the messages handled by tag, destination and outcome, and the time
+// spent on them, once EnableMetrics is called. Inside Timeout a message whose
+// context is past its deadline counts as a timeout, a panic is recorded then
+// propagated.
+func Metrics() database.ConsumerMiddleware {
+	return func(next database.ConsumerCallback) database.ConsumerCallback {
+		return func(msg database.Messages, done database.ConsumerCallbackIsDone) {
+			start := time.Now()
+			defer func() {
+				outcome := OutcomeOK
+				if r := recover(); r != nil {
+					metrics.ConsumerHandled(msg.Metadata().Tag, msg.Exchange(), OutcomePanic, time.Since(start))
+					panic(r)
+				}
+				if ctx := msg.Context(); ctx != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					outcome = OutcomeTimeout
+				}
+				metrics.ConsumerHandled(msg.Metadata().Tag, msg.Exchange(), outcome, time.Since(start))
+			}()
+			next(msg, done)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsTag(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if err := metrics.Register(reg); err != nil {
+		t.Fatal(err)
+	}
+
+	callback := Chain(func(msg database.Messages, done database.ConsumerCallbackIsDone) {}, Metrics())
+	for _, tag := range []string{"orders", "billing"} {
+		callback(database.NewMessage(nil, "events", "", database.EncodingJSON, database.MessageInfo{
+			Metadata: database.MessageMetadata{Tag: tag},
+		}), database.ConsumerCallbackIsDone{})
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := map[string]bool{}
+	for _, family := range families {
+		if family.GetName() != metrics.Namespace+"_consumer_handled_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "tag" {
+					tags[label.GetValue()] = true
+				}
+			}
+		}
+	}
+
+	if !tags["orders"] || !tags["billing"] {
+		t.Errorf("tags = %v, want orders and billing", tags)
+	}
+}
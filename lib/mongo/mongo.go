@@ -2,95 +2,216 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/backoff"
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
+	"github.com/fajarardiyanto/flt-go-database/lib/redact"
+	"github.com/fajarardiyanto/flt-go-database/lib/secret"
+	"github.com/fajarardiyanto/flt-go-database/lib/tlsutil"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	"github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 type service struct {
-	reconnectAt int
-	db          *mongo.Client
-	log         logger.Logger
-	lastTimeout time.Duration
-	pool        *redis.PoolStats
-	config      interfaces.MongoProviderConfig
-	sync.Mutex
+	tag    string
+	db     *mongo.Client
+	log    logger.Logger
+	pool   *redis.PoolStats
+	config interfaces.MongoProviderConfig
+	conn   *backoff.Connection
+	sync.RWMutex
 }
 
 func NewMongo(log logger.Logger, config interfaces.MongoProviderConfig) interfaces.Mongo {
+	return NewMongoWithTag("default", log, config)
+}
+
+func NewMongoWithTag(tag string, log logger.Logger, config interfaces.MongoProviderConfig) interfaces.Mongo {
 	log.Debug("Mongo Client %s:%d has been registered", config.Host, config.Port)
-	return &service{
+	s := &service{
+		tag:    strings.ToLower(tag),
 		config: config,
 		log:    log,
 	}
+	s.conn = backoff.NewConnection("mongo", s.tag, log, config.Backoff, config.StartInterval, config.MaxError, config.AutoReconnect)
+	s.conn.Connect, s.conn.Ping = s.connect, s.Ping
+	return s
+}
+
+// Init connects the client, a disabled provider is not an error.
+func (s *service) Init() error {
+	if err := s.start(context.Background()); err != nil && !dberrors.Is(err, dberrors.ErrDisabled) {
+		return err
+	}
+	return nil
 }
 
-func (s *service) Init() (err error) {
+// start connects the client, then watches it with AutoReconnect.
+func (s *service) start(ctx context.Context) (err error) {
 	if !s.config.Enable {
 		msg := "aborted, mongo database not enable in config, double check configuration again"
 		s.log.Error(msg)
-		return nil
+		return dberrors.New("mongo", s.tag, "connect", dberrors.ErrDisabled)
+	}
+
+	if _, err := s.uri(ctx); err != nil {
+		s.log.Error(err)
+		return err
+	}
+
+	return s.conn.Start(ctx)
+}
+
+// uri returns the URL of the config, mongodb:// or mongodb+srv://, when set,
+// otherwise builds it from Host, Port and the credentials. The secrets are resolved
+// each time the client connects, the driver keeps them for the connections of its
+// pool.
+func (s *service) uri(ctx context.Context) (string, error) {
+	config := s.config
+	if err := secret.ResolveAll(ctx, &config.URL, &config.Username, &config.Password); err != nil {
+		return "", err
+	}
+
+	if len(config.URL) == 0 {
+		u := url.URL{Scheme: "mongodb", Host: net.JoinHostPort(config.Host, strconv.Itoa(config.Port))}
+		if config.Username != "" {
+			u.User = url.UserPassword(config.Username, config.Password)
+		}
+		return u.String(), nil
+	}
+
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid mongo url %q: %v", redact.URL(config.URL), errors.Unwrap(err))
+	}
+	if u.Scheme != "mongodb" && u.Scheme != "mongodb+srv" {
+		return "", fmt.Errorf("invalid mongo url: scheme must be mongodb or mongodb+srv, got %q", u.Scheme)
+	}
+	if len(u.Host) == 0 {
+		return "", fmt.Errorf("invalid mongo url: missing host")
+	}
+	return config.URL, nil
+}
+
+func (s *service) connect(ctx context.Context) (err error) {
+	addr, err := s.uri(ctx)
+	if err != nil {
+		return err
 	}
 
-	s.log.Debug("Connecting to mongo database server %s:%d", s.config.Host, s.config.Port)
+	if u, err := url.Parse(addr); err == nil {
+		s.log.Debug("Connecting to mongo database server %s", u.Host)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.TimeoutConnection)*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.config.TimeoutConnection)*time.Millisecond)
 	defer cancel()
 
-	var addr string
-	addr = fmt.Sprintf("mongodb://%s:%d", s.config.Host, s.config.Port)
-	if s.config.Username != "" {
-		addr = fmt.Sprintf("mongodb://%s:%s@%s:%d", s.config.Username, s.config.Password, s.config.Host, s.config.Port)
+	opts := options.Client().ApplyURI(addr).SetPoolMonitor(&event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			metrics.MongoPoolEvent(s.tag, e.Type)
+		},
+	}).SetMonitor((&commandTracing{tag: s.tag}).monitor())
+
+	if s.config.TLS.Enable {
+		tlsConfig, err := tlsutil.Config(s.config.TLS)
+		if err != nil {
+			return err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	if err = opts.Validate(); err != nil {
+		return fmt.Errorf("invalid mongo url: %w", err)
 	}
 
-	s.db, err = mongo.Connect(ctx, options.Client().ApplyURI(addr))
+	db, err := mongo.Connect(ctx, opts)
 	if err != nil {
-		return s.OnInitError(err)
+		return err
+	}
+
+	// the driver connects lazily, the ping makes a server down fail the attempt
+	if err = db.Ping(ctx, readpref.Primary()); err != nil {
+		_ = db.Disconnect(context.Background())
+		return err
+	}
+
+	s.Lock()
+	previous := s.db
+	s.db = db
+	s.Unlock()
+
+	if previous != nil {
+		_ = previous.Disconnect(ctx)
 	}
 
-	s.log.Info("Success to connect mongo %s", addr)
+	s.log.Info("Success to connect mongo %s", redact.URL(addr))
 
 	return nil
 }
 
-func (s *service) OnInitError(e error) (err error) {
-	if s.reconnectAt >= s.config.MaxError {
-		return e
-	}
+// client returns the connected client, nil before Init and after Close.
+func (s *service) client() *mongo.Client {
+	s.RLock()
+	defer s.RUnlock()
+	return s.db
+}
 
-	s.reconnectAt++
-	if !s.config.AutoReconnect {
-		return e
+func (s *service) Ping(ctx context.Context) error {
+	db := s.client()
+	if db == nil {
+		return dberrors.New("mongo", s.tag, "ping", dberrors.ErrNotConnected)
 	}
 
-	var ttm = 2
-	if s.lastTimeout.Seconds() == 0 {
-		ttm = 1
-		s.lastTimeout = time.Duration(s.config.StartInterval) * time.Second
-	}
+	return db.Ping(ctx, readpref.Primary())
+}
+
+func (s *service) Close(ctx context.Context) error {
+	s.Lock()
+	db := s.db
+	s.db = nil
+	s.Unlock()
+	s.conn.Stop()
 
-	s.lastTimeout = time.Duration(int(s.lastTimeout.Seconds())*ttm) * time.Second
-	s.log.Error(e)
-	s.log.Warning("Reconnecting in %s", s.lastTimeout)
-	time.Sleep(s.lastTimeout)
+	if db == nil {
+		return nil
+	}
 
-	return s.Init()
+	return db.Disconnect(ctx)
 }
 
+// SetDatabase returns the database of the client, nil when not connected.
 func (s *service) SetDatabase(db string) *mongo.Database {
-	return s.db.Database(db)
+	client := s.client()
+	if client == nil {
+		return nil
+	}
+	return client.Database(db)
 }
 
 func (s *service) LoadPostChannel(ctx context.Context, db, table string, filter bson.M, res chan<- []bson.M, opt ...*options.FindOptions) {
 	now := time.Now()
 
-	cur, err := s.db.Database(db).Collection(table).Find(ctx, filter, opt...)
+	client := s.client()
+	if client == nil {
+		s.log.Error(dberrors.New("mongo", s.tag, "find", dberrors.ErrNotConnected))
+		return
+	}
+
+	cur, err := client.Database(db).Collection(table).Find(ctx, filter, opt...)
 	if err != nil {
 		s.log.Error(err)
 		return
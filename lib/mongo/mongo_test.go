@@ -0,0 +1,74 @@
+package mongo
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/secret"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+func TestReconnectResolvesRotatedSecret(t *testing.T) {
+	var password atomic.Value
+	password.Store("first")
+	var resolved int32
+	secret.Register("mongo-rotate-test", interfaces.SecretProviderFunc(func(ctx context.Context, reference string) (string, error) {
+		atomic.AddInt32(&resolved, 1)
+		return password.Load().(string), nil
+	}))
+	defer secret.Register("mongo-rotate-test", nil)
+
+	lg := log.NewLib()
+	lg.Init("Test Mongo")
+
+	s := NewMongoWithTag("rotate", lg, interfaces.MongoProviderConfig{
+		Enable:            true,
+		Host:              "127.0.0.1",
+		Port:              1,
+		Username:          "root",
+		Password:          "mongo-rotate-test:password",
+		TimeoutConnection: 50,
+	}).(*service)
+
+	addr, err := s.uri(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(addr, "root:first@") {
+		t.Fatalf("uri = %s, want the first password", addr)
+	}
+
+	password.Store("rotated")
+
+	// the watch reconnects through Connect, which builds the uri again
+	before := atomic.LoadInt32(&resolved)
+	_ = s.conn.Connect(context.Background())
+	if atomic.LoadInt32(&resolved) == before {
+		t.Fatal("reconnect did not resolve the password again")
+	}
+
+	if addr, err = s.uri(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(addr, "root:rotated@") {
+		t.Errorf("uri = %s, want the rotated password", addr)
+	}
+}
+
+func TestNotConnected(t *testing.T) {
+	lg := log.NewLib()
+	lg.Init("Test Mongo")
+
+	s := NewMongoWithTag("idle", lg, interfaces.MongoProviderConfig{}).(*service)
+	if db := s.SetDatabase("test"); db != nil {
+		t.Errorf("SetDatabase = %v before connecting, want nil", db)
+	}
+
+	if _, err := s.V2().Find(context.Background(), "test", "test", nil); !dberrors.Is(err, dberrors.ErrNotConnected) {
+		t.Errorf("Find = %v before connecting, want %v", err, dberrors.ErrNotConnected)
+	}
+}
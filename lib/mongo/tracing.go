@@ -0,0 +1,48 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandTracing creates a client span around every mongo command, the spans are
+// matched to their result by request id.
+type commandTracing struct {
+	tag   string
+	spans sync.Map
+}
+
+func (m *commandTracing) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+func (m *commandTracing) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	_, span := tracing.Start(ctx, "mongo."+evt.CommandName, trace.SpanKindClient,
+		semconv.DBSystemMongoDB,
+		semconv.DBNameKey.String(evt.DatabaseName),
+		semconv.DBOperationKey.String(evt.CommandName),
+		tracing.TagKey.String(m.tag))
+	m.spans.Store(evt.RequestID, span)
+}
+
+func (m *commandTracing) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	if val, ok := m.spans.LoadAndDelete(evt.RequestID); ok {
+		tracing.End(val.(trace.Span), nil)
+	}
+}
+
+func (m *commandTracing) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	if val, ok := m.spans.LoadAndDelete(evt.RequestID); ok {
+		tracing.End(val.(trace.Span), errors.New(evt.Failure))
+	}
+}
@@ -0,0 +1,31 @@
+package mongo
+
+import (
+	"context"
+
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	databasev2 "github.com/fajarardiyanto/flt-go-database/interfaces/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type clientV2 struct {
+	*service
+}
+
+// V2 returns the client with the context-first interface, Connect reports a
+// disabled provider as ErrDisabled.
+func (s *service) V2() databasev2.Mongo {
+	return clientV2{s}
+}
+
+func (v clientV2) Connect(ctx context.Context) error {
+	return v.start(ctx)
+}
+
+func (v clientV2) Find(ctx context.Context, db, table string, filter bson.M, opt ...*options.FindOptions) ([]bson.M, error) {
+	if v.client() == nil {
+		return nil, dberrors.New("mongo", v.tag, "find", dberrors.ErrNotConnected)
+	}
+	return databasev2.Find(ctx, v.service, db, table, filter, opt...)
+}
@@ -0,0 +1,13 @@
+package lib
+
+import (
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/redact"
+)
+
+// SetPayloadLogging sets how the message and query bodies are written in the logs
+// of every client of the process, PayloadHash by default. The limit only applies to
+// PayloadTruncate.
+func SetPayloadLogging(mode database.PayloadLogging, limit int) {
+	redact.SetPayloadLogging(mode, limit)
+}
@@ -1,26 +1,41 @@
 package rabbitmq
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
 	"fmt"
 	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/backoff"
+	"github.com/fajarardiyanto/flt-go-database/lib/compress"
+	"github.com/fajarardiyanto/flt-go-database/lib/events"
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	"github.com/fajarardiyanto/flt-go-utils/hash"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/ratelimit"
 	"google.golang.org/grpc/metadata"
 	"sync"
-	"time"
 )
 
 type Consumer struct {
+	tag         string
 	callback    database.ConsumerCallback
 	options     database.RabbitMQOptions
 	config      database.RabbitMQProviderConfig
 	store       *Stores
 	logger      logger.Logger
 	dialer      *Dialer
+	session     *Session
 	alreadySubs bool
+	closed      bool
+	attempt     int
+	ctx         context.Context
+	cancel      context.CancelFunc
+	inflight    sync.WaitGroup
+	limit       ratelimit.Limiter
+	jobs        chan func()
+	workers     sync.Once
 	sync.RWMutex
 }
 
@@ -28,21 +43,112 @@ func NewConsumer(lg logger.Logger, dialer *Dialer, config database.RabbitMQProvi
 	options database.RabbitMQOptions,
 	callback database.ConsumerCallback,
 	store *Stores) *Consumer {
-	return &Consumer{
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Consumer{
 		dialer:   dialer,
 		store:    store,
 		callback: callback,
 		options:  options,
 		config:   config,
 		logger:   lg,
+		ctx:      ctx,
+		cancel:   cancel,
+		jobs:     make(chan func()),
+	}
+	if options.RateLimit > 0 {
+		c.limit = ratelimit.New(options.RateLimit)
+	}
+	return c
+}
+
+// prefetch is the Qos of the channel of the consumer, zero leaves it unlimited.
+func (c *Consumer) prefetch() int {
+	switch {
+	case c.options.PrefetchCount > 0:
+		return c.options.PrefetchCount
+	case c.options.Sequential:
+		return 1
+	}
+	return c.options.Workers
+}
+
+// begin counts a callback in flight, false once the consumer is closed. The count is
+// taken under the lock closing the consumer so Close never waits while it grows.
+func (c *Consumer) begin() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.closed {
+		return false
+	}
+	c.inflight.Add(1)
+	return true
+}
+
+// run runs the callback of a delivery on a worker, or in place when sequential. It
+// blocks while every worker is busy, holding back the next deliveries, until the
+// consumer is closed. It returns false for the deliveries received once closed, they
+// are dropped and the broker requeues the unacked ones with the channel.
+func (c *Consumer) run(job func()) bool {
+	if c.limit != nil {
+		c.limit.Take()
+	}
+
+	if !c.begin() {
+		return false
+	}
+	switch {
+	case c.options.Sequential:
+		defer c.inflight.Done()
+		job()
+	case c.options.Workers > 0:
+		c.workers.Do(func() {
+			for i := 0; i < c.options.Workers; i++ {
+				go c.work()
+			}
+		})
+
+		select {
+		case c.jobs <- job:
+		case <-c.ctx.Done():
+			c.inflight.Done()
+			return false
+		}
+	default:
+		go func() {
+			defer c.inflight.Done()
+			job()
+		}()
+	}
+	return true
+}
+
+func (c *Consumer) work() {
+	for {
+		select {
+		case job := <-c.jobs:
+			job()
+			c.inflight.Done()
+		case <-c.ctx.Done():
+			return
+		}
 	}
 }
 
 func (c *Consumer) Init() {
+	if c.isClosed() {
+		return
+	}
+
 	if c.config.DedicatedConnection {
 		ctx := context.Background()
 		dialer := NewDialer(c.options.Exchange, c.logger)
-		dialer.Dial(ctx, c.config, c.onError, nil)
+		c.Lock()
+		c.dialer = dialer
+		c.Unlock()
+		dialer.Dial(ctx, c.config, c.onError, func() {
+			events.Connected("rabbitmq", c.tag)
+		})
 		c.Subscribe(dialer.Session, c.Write())
 	} else {
 
@@ -59,15 +165,94 @@ func (c *Consumer) Init() {
 	}
 }
 
+func (c *Consumer) IsConnected() bool {
+	c.RLock()
+	dialer := c.dialer
+	c.RUnlock()
+	return dialer != nil && dialer.IsConnected()
+}
+
+func (c *Consumer) isClosed() bool {
+	c.RLock()
+	closed := c.closed
+	c.RUnlock()
+	return closed
+}
+
+// Close stops consuming and waits for the in-flight callbacks until the context is
+// done. A dedicated connection is closed as well.
+func (c *Consumer) Close(ctx context.Context) error {
+	c.Lock()
+	c.closed = true
+	c.cancel()
+	session := c.session
+	dialer := c.dialer
+	c.Unlock()
+
+	if session != nil && session.Channel != nil && !session.Channel.IsClosed() {
+		if err := session.Channel.Close(); err != nil {
+			c.logger.Error("[%s] %s", c.options.Exchange, err)
+		}
+	}
+
+	if c.config.DedicatedConnection && dialer != nil {
+		if err := dialer.Close(); err != nil {
+			c.logger.Error("[%s] %s", c.options.Exchange, err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (c *Consumer) onError(err error) {
+	if c.isClosed() {
+		return
+	}
+
 	if err != nil {
 		c.logger.Trace("[%s] %s", c.options.Exchange, err)
 	}
 
 	if c.config.DedicatedConnection {
-		reconnectDuration := time.Duration(c.config.ReconnectDuration) * time.Second
-		c.logger.Warning("[%s] reconnecting in %s", c.options.Exchange, reconnectDuration)
-		time.Sleep(reconnectDuration)
+		if c.config.ReconnectDuration <= 0 {
+			c.config.ReconnectDuration = 5
+		}
+
+		c.Lock()
+		c.attempt++
+		attempt := c.attempt
+		subscribed := c.alreadySubs
+		c.alreadySubs = false
+		c.Unlock()
+
+		if subscribed {
+			events.Disconnected("rabbitmq", c.tag, err)
+		}
+
+		policy := backoff.FromConfig(c.config.Backoff, c.config.ReconnectDuration, -1)
+		if policy.Exhausted(attempt) {
+			c.logger.Error("[%s] giving up reconnecting after %d attempts", c.options.Exchange, attempt)
+			events.GaveUp("rabbitmq", c.tag, attempt, err)
+			return
+		}
+
+		delay := policy.Delay(attempt)
+		c.logger.Warning("[%s] reconnecting in %s", c.options.Exchange, delay)
+		events.Reconnecting("rabbitmq", c.tag, attempt, delay, err)
+		if err := backoff.Sleep(c.ctx, delay); err != nil {
+			return
+		}
 		c.logger.Info("[%s] reconnecting now ...", c.options.Exchange)
 	}
 
@@ -82,6 +267,7 @@ func (c *Consumer) onError(err error) {
 func (c *Consumer) OnSession(queue string, sub Session, messages chan<- Message) {
 	c.Lock()
 	c.alreadySubs = true
+	c.session = &sub
 	c.Unlock()
 
 	if _, err := sub.QueueDeclare(
@@ -102,38 +288,73 @@ func (c *Consumer) OnSession(queue string, sub Session, messages chan<- Message)
 		}
 	}
 
+	if prefetch := c.prefetch(); prefetch > 0 {
+		if err := sub.Qos(prefetch, 0, false); err != nil {
+			c.onError(fmt.Errorf("cannot set Qos %d: %v", prefetch, err))
+			return
+		}
+	}
+
 	deliveries, err := sub.Consume(queue, "", false, false, false, c.options.NoWait, nil)
 	if err != nil {
 		c.onError(fmt.Errorf("cannot Consume from: %q, %v", queue, err))
 		return
 	}
 
+	c.Lock()
+	c.attempt = 0
+	c.Unlock()
+
 	if len(c.options.RoutingKey) != 0 {
 		c.logger.Success("Subscribed exchange %s, routing %s", c.options.Exchange, c.options.RoutingKey)
 	} else {
 		c.logger.Success("Subscribed exchange %s", c.options.Exchange)
 	}
+	events.ConsumerSubscribed("rabbitmq", c.tag, c.options.Exchange)
 
 	for msg := range deliveries {
-		messages <- Message{
-			Headers: msg.Headers,
-			Body:    msg.Body,
+		metrics.RabbitMQConsumed(c.tag, c.options.Exchange)
+		line := Message{
+			MessageID:   msg.MessageId,
+			Headers:     msg.Headers,
+			Body:        msg.Body,
+			Timestamp:   msg.Timestamp,
+			DeliveryTag: msg.DeliveryTag,
+			Exchange:    msg.Exchange,
+			RoutingKey:  msg.RoutingKey,
+			Redelivered: msg.Redelivered,
+		}
+		if c.options.ManualAck {
+			line.Acknowledger = msg.Acknowledger
 		}
+		messages <- line
 
+		if c.options.ManualAck {
+			continue
+		}
 		if err := sub.Ack(msg.DeliveryTag, false); err != nil {
 			c.logger.Error("Can't confirm ack delivery %s", err)
 		}
 	}
 }
 
-func (c *Consumer) Subscribe(sessions chan chan Session, messages chan<- Message) {
-
-	queue := hash.CreateSmallHash(10, c.options.Exchange, c.options.RoutingKey)
+// queue is the name of the queue of the consumer, the exchange or the hash of the
+// exchange and routing key when the routing key is set.
+func (c *Consumer) queue() string {
 	if len(c.options.RoutingKey) == 0 {
-		queue = c.options.Exchange
+		return c.options.Exchange
 	}
+	return hash.CreateSmallHash(10, c.options.Exchange, c.options.RoutingKey)
+}
+
+func (c *Consumer) Subscribe(sessions chan chan Session, messages chan<- Message) {
+
+	queue := c.queue()
 
 	for session := range sessions {
+		if c.isClosed() {
+			return
+		}
 		sub := <-session
 		c.OnSession(queue, sub, messages)
 	}
@@ -152,31 +373,95 @@ func (c *Consumer) Write() chan<- Message {
 			}
 
 			md := metadata.New(mdd)
-			ctx := metadata.NewIncomingContext(context.Background(), md)
+			ctx := tracing.Extract(metadata.NewIncomingContext(context.Background(), md), line.Headers)
 
+			ack := acknowledger(line)
 			if c.callback == nil {
+				_ = ack.Ack()
+				continue
+			}
 
+			body, err := compress.Decompress(compress.Header(line.Headers), line.Body)
+			if err != nil {
+				c.logger.Error("Failed to decompress message %s", err)
+				_ = ack.Reject()
+				continue
 			}
 
-			if c.options.Encoding == database.EncodingBase64Gob {
-				if data, err := base64.StdEncoding.DecodeString(string(line.Body)); err == nil {
-					msg := database.NewEncoder(bytes.NewBuffer(data),
-						c.options.Exchange, c.options.RoutingKey,
-						database.EncodingBase64Gob)
-					msg.SetContext(ctx)
-
-					if c.callback != nil {
-						go c.callback(msg, database.ConsumerCallbackIsDone{
-							EndRequest: func() {
-							},
-						})
-					}
-				} else {
-					c.logger.Error(err)
-				}
+			msg := database.NewMessage(body,
+				c.options.Exchange, c.options.RoutingKey,
+				c.options.Encoding, database.MessageInfo{
+					ID:           line.MessageID,
+					Headers:      line.Headers,
+					Timestamp:    line.Timestamp,
+					Redeliveries: redeliveries(line),
+					Metadata: database.MessageMetadata{Tag: c.tag, AMQP: &database.AMQPMetadata{
+						DeliveryTag: line.DeliveryTag,
+						Exchange:    line.Exchange,
+						RoutingKey:  line.RoutingKey,
+						Redelivered: line.Redelivered,
+					}},
+				})
+
+			spanCtx, span := tracing.Start(ctx, c.options.Exchange+" receive", trace.SpanKindConsumer,
+				semconv.MessagingSystemKey.String("rabbitmq"),
+				semconv.MessagingDestinationKey.String(c.options.Exchange),
+				semconv.MessagingOperationReceive,
+				tracing.TagKey.String(c.tag))
+			msg.SetContext(spanCtx)
+
+			done := database.ConsumerCallbackIsDone{
+				EndRequest: func() {
+				},
+			}
+			if line.Acknowledger != nil {
+				done.Acknowledger = ack
+			}
+
+			if !c.run(func() {
+				defer span.End()
+				c.callback(msg, done)
+			}) {
+				span.End()
 			}
 
 		}
 	}()
 	return lines
 }
+
+// redeliveries returns the x-delivery-count header of the quorum queues, or 1 for a
+// message redelivered by a classic queue.
+func redeliveries(line Message) int {
+	switch val := line.Headers["x-delivery-count"].(type) {
+	case int64:
+		return int(val)
+	case int32:
+		return int(val)
+	case int:
+		return val
+	}
+	if line.Redelivered {
+		return 1
+	}
+	return 0
+}
+
+// acknowledger settles the delivery of the line once, a no-op for the deliveries
+// acked on receipt.
+func acknowledger(line Message) database.Acknowledger {
+	if line.Acknowledger == nil {
+		return database.NewDelivery(noop, func(bool) error { return nil }, noop)
+	}
+	return database.NewDelivery(func() error {
+		return line.Acknowledger.Ack(line.DeliveryTag, false)
+	}, func(requeue bool) error {
+		return line.Acknowledger.Nack(line.DeliveryTag, false, requeue)
+	}, func() error {
+		return line.Acknowledger.Reject(line.DeliveryTag, false)
+	})
+}
+
+func noop() error {
+	return nil
+}
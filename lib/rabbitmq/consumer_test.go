@@ -0,0 +1,40 @@
+package rabbitmq
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+func TestConsumerRunAfterClose(t *testing.T) {
+	for _, options := range []database.RabbitMQOptions{
+		{Exchange: "default"},
+		{Exchange: "sequential", Sequential: true},
+		{Exchange: "workers", Workers: 2},
+	} {
+		options := options
+		t.Run(options.Exchange, func(t *testing.T) {
+			c := NewConsumer(nil, nil, database.RabbitMQProviderConfig{}, options, nil, nil)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					c.run(func() {})
+				}()
+			}
+
+			if err := c.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			wg.Wait()
+
+			if c.run(func() { t.Error("job run after Close") }) {
+				t.Error("run = true after Close")
+			}
+		})
+	}
+}
@@ -4,14 +4,29 @@ import (
 	"context"
 	"fmt"
 	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/secret"
+	"github.com/fajarardiyanto/flt-go-database/lib/tlsutil"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"net"
+	"net/url"
+	"strconv"
 	"sync"
+	"time"
 )
 
 type Message struct {
-	Headers map[string]interface{}
-	Body    []byte
+	MessageID   string
+	Headers     map[string]interface{}
+	Body        []byte
+	Timestamp   time.Time
+	DeliveryTag uint64
+	Exchange    string
+	RoutingKey  string
+	Redelivered bool
+	// Acknowledger is set for the deliveries left unsettled, see
+	// RabbitMQOptions.ManualAck.
+	Acknowledger amqp.Acknowledger
 }
 
 type Dialer struct {
@@ -19,6 +34,9 @@ type Dialer struct {
 	logger    logger.Logger
 	Session   chan chan Session
 	connected bool
+	closed    bool
+	conn      *amqp.Connection
+	cancel    context.CancelFunc
 	sync.RWMutex
 }
 
@@ -46,20 +64,104 @@ func (c *Dialer) IsConnected() bool {
 	return connected
 }
 
+func (c *Dialer) IsClosed() bool {
+	c.RLock()
+	closed := c.closed
+	c.RUnlock()
+	return closed
+}
+
+// Close tears the connection down for good, the dialer will not reconnect anymore.
+func (c *Dialer) Close() error {
+	c.Lock()
+	c.closed = true
+	conn := c.conn
+	cancel := c.cancel
+	c.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	if conn != nil && !conn.IsClosed() {
+		return conn.Close()
+	}
+
+	return nil
+}
+
+// uri returns the URL of the config, amqp:// or amqps:// with an optional vhost,
+// when set, otherwise builds it from Host, Port and the credentials.
+func uri(ctx context.Context, config interfaces.RabbitMQProviderConfig) (string, error) {
+	if err := secret.ResolveAll(ctx, &config.URL, &config.Username, &config.Password); err != nil {
+		return "", err
+	}
+
+	if len(config.URL) == 0 {
+		u := url.URL{
+			Scheme: "amqp",
+			User:   url.UserPassword(config.Username, config.Password),
+			Host:   net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+			Path:   "/",
+		}
+		if config.TLS.Enable {
+			u.Scheme = "amqps"
+		}
+		return u.String(), nil
+	}
+
+	u, err := amqp.ParseURI(config.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid rabbitmq url: %w", err)
+	}
+	if config.TLS.Enable && u.Scheme != "amqps" {
+		return "", fmt.Errorf("invalid rabbitmq url: tls is enabled, the scheme must be amqps")
+	}
+	return config.URL, nil
+}
+
+func address(url string) string {
+	uri, err := amqp.ParseURI(url)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d/%s", uri.Host, uri.Port, uri.Vhost)
+}
+
 type OnError func(error)
 type OnConnected func()
 
 func (c *Dialer) Dial(cx context.Context, config interfaces.RabbitMQProviderConfig, onError OnError, onConnected OnConnected) {
-	ctx, cancel := context.WithCancel(cx)
+	if c.IsClosed() {
+		return
+	}
 
-	url := fmt.Sprintf("amqp://%s:%s@%s:%d", config.Username,
-		config.Password, config.Host, config.Port)
+	addr, err := uri(cx, config)
+	if err != nil {
+		c.logger.Error("[%s] %s", c.id, err)
+		onError(err)
+		return
+	}
 
-	conn, err := amqp.Dial(url)
+	tlsConfig, err := tlsutil.Config(config.TLS)
+	if err != nil {
+		c.logger.Error("[%s] %s", c.id, err)
+		onError(err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(cx)
+
+	var conn *amqp.Connection
+	if tlsConfig != nil {
+		conn, err = amqp.DialTLS(addr, tlsConfig)
+	} else {
+		conn, err = amqp.Dial(addr)
+	}
 	if err != nil {
 		c.logger.Error("[%s] %s", c.id, err)
 		cancel()
-		onError(nil)
+		onError(err)
 		return
 	}
 
@@ -68,10 +170,12 @@ func (c *Dialer) Dial(cx context.Context, config interfaces.RabbitMQProviderConf
 
 	c.Lock()
 	c.connected = true
+	c.conn = conn
+	c.cancel = cancel
 	c.Session = make(chan chan Session)
 	c.Unlock()
 
-	c.logger.Success("[%s] connected to : %s:%d", c.id, config.Host, config.Port)
+	c.logger.Success("[%s] connected to : %s", c.id, address(addr))
 
 	go func() {
 		sess := make(chan Session)
@@ -85,7 +189,9 @@ func (c *Dialer) Dial(cx context.Context, config interfaces.RabbitMQProviderConf
 				c.Lock()
 				c.connected = false
 				c.Unlock()
-				go onError(nil)
+				if !c.IsClosed() {
+					go onError(nil)
+				}
 				return
 			case c.Session <- sess:
 			case <-ctx.Done():
@@ -93,7 +199,9 @@ func (c *Dialer) Dial(cx context.Context, config interfaces.RabbitMQProviderConf
 				c.Lock()
 				c.connected = false
 				c.Unlock()
-				go onError(nil)
+				if !c.IsClosed() {
+					go onError(nil)
+				}
 				return
 			}
 
@@ -1,17 +1,16 @@
 package rabbitmq
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/gob"
+	"errors"
+	"fmt"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/compress"
+	"github.com/fajarardiyanto/flt-go-database/lib/metrics"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	"github.com/fajarardiyanto/flt-go-utils/hash"
-	databaseproto "github.com/fajarardiyanto/module-proto/go/modules/database"
 	amqp "github.com/rabbitmq/amqp091-go"
-	"google.golang.org/protobuf/proto"
-	"google.golang.org/protobuf/types/known/anypb"
 	"sync"
 	"time"
 )
@@ -32,6 +31,7 @@ type PendingQue struct {
 }
 
 type Producer struct {
+	tag         string
 	options     database.RabbitMQOptions
 	config      database.RabbitMQProviderConfig
 	logger      logger.Logger
@@ -40,6 +40,9 @@ type Producer struct {
 	pending     chan MsgSend
 	pendingQue  []PendingQue
 	alreadySubs bool
+	closed      bool
+	stop        chan struct{}
+	inflight    sync.WaitGroup
 	sync.RWMutex
 }
 
@@ -53,6 +56,7 @@ func NewProducer(lg logger.Logger, dialer *Dialer,
 		config:  config,
 		logger:  lg,
 		store:   store,
+		stop:    make(chan struct{}),
 	}
 	return pr
 }
@@ -60,15 +64,23 @@ func NewProducer(lg logger.Logger, dialer *Dialer,
 func (c *Producer) Init() {
 	c.RLock()
 	alreadySub := c.alreadySubs
-	dialer := c.dialer
 	c.RUnlock()
 
-	if !alreadySub && dialer.IsConnected() {
+	if !alreadySub && c.IsConnected() {
 		c.Publish(c.dialer.Session)
 	}
 
 }
 
+// IsConnected reports whether the connection of the producer is up, a producer of a
+// client with dedicated connections has none.
+func (c *Producer) IsConnected() bool {
+	c.RLock()
+	dialer := c.dialer
+	c.RUnlock()
+	return dialer != nil && dialer.IsConnected()
+}
+
 func (c *Producer) Publish(sessions chan chan Session) {
 	if len(c.pendingQue) != 0 {
 		go func() {
@@ -77,7 +89,9 @@ func (c *Producer) Publish(sessions chan chan Session) {
 			pQue := c.pendingQue
 			c.RUnlock()
 			for _, s := range pQue {
-				c.SendingData(s.id, s.key, s.body, s.header, s.cb)
+				if err := c.SendingData(context.Background(), s.id, s.key, s.body, s.header, s.cb); err != nil {
+					c.logger.Error("Failed to send message %s from sending que: %s", s.id, err)
+				}
 			}
 
 			c.Lock()
@@ -87,11 +101,22 @@ func (c *Producer) Publish(sessions chan chan Session) {
 		}()
 	}
 
+	// the messages published and not confirmed yet, they are no longer waited for
+	// once the channel is closed
+	unconfirmed := 0
+	defer func() {
+		for ; unconfirmed > 0; unconfirmed-- {
+			c.inflight.Done()
+		}
+	}()
+
 	for session := range sessions {
 		c.Lock()
 		c.alreadySubs = true
 		confirm := make(chan amqp.Confirmation)
-		c.pending = make(chan MsgSend, 1)
+		if c.pending == nil {
+			c.pending = make(chan MsgSend, 1)
+		}
 		pub := <-session
 		c.Unlock()
 
@@ -116,7 +141,7 @@ func (c *Producer) Publish(sessions chan chan Session) {
 
 		if err := pub.Confirm(false); err != nil {
 			c.logger.Warning("publisher confirms not supported")
-			close(confirm)
+			confirm = nil
 		} else {
 			pub.NotifyPublish(confirm)
 		}
@@ -142,15 +167,28 @@ func (c *Producer) Publish(sessions chan chan Session) {
 				if qq, err := pub.Channel.QueueDeclare(msg.Name,
 					false, false, false, true, nil); err != nil {
 					c.logger.Error("QueueDeclare: %s", err)
+					c.inflight.Done()
 				} else {
+					contentType, _ := msg.Headers[database.HeaderContentType].(string)
 					if err = pub.Channel.PublishWithContext(context.Background(), "", qq.Name, false, false, amqp.Publishing{
-						Headers: msg.Headers,
-						Body:    msg.Data,
+						Headers:         msg.Headers,
+						ContentType:     contentType,
+						ContentEncoding: compress.Header(msg.Headers),
+						MessageId:       msg.ID,
+						Timestamp:       time.Now(),
+						Body:            msg.Data,
 					}); err != nil {
 						c.logger.Error("Publish: %s", err)
+						c.inflight.Done()
 					} else {
+						metrics.RabbitMQPublished(c.tag, msg.Name)
 						c.logger.Trace("Message sending que (%s) ID : %s",
 							msg.Name, msg.ID)
+						if confirm != nil {
+							unconfirmed++
+						} else {
+							c.inflight.Done()
+						}
 					}
 				}
 
@@ -162,6 +200,11 @@ func (c *Producer) Publish(sessions chan chan Session) {
 					return
 				}
 
+				if unconfirmed > 0 {
+					unconfirmed--
+					c.inflight.Done()
+				}
+				metrics.RabbitMQConfirmed(c.tag, confirmed.Ack)
 				if !confirmed.Ack {
 					c.logger.Warning("Failed delivery of delivery tag: %d", confirmed.DeliveryTag)
 				}
@@ -171,13 +214,96 @@ func (c *Producer) Publish(sessions chan chan Session) {
 	}
 }
 
-func (c *Producer) SendingData(id string, key string, body interface{}, headers map[string]interface{}, cb database.ConsumerCallback) {
+// Close sends the messages queued while disconnected and waits for the messages in
+// flight to be published, and confirmed when the channel confirms them, until the
+// context is done.
+func (c *Producer) Close(ctx context.Context) error {
+	c.Lock()
+	pQue := c.pendingQue
+	c.pendingQue = []PendingQue{}
+	dialer := c.dialer
+	c.Unlock()
 
+	var err error
+	if len(pQue) != 0 {
+		if dialer == nil || !dialer.IsConnected() {
+			c.logger.Warning("Dropping %d messages from sending que, not connected to rabbitmq server", len(pQue))
+			err = dberrors.New("rabbitmq", c.tag, "close", fmt.Errorf("%w, %d messages dropped", dberrors.ErrNotConnected, len(pQue)))
+		} else {
+			for _, s := range pQue {
+				if e := c.SendingData(ctx, s.id, s.key, s.body, s.header, s.cb); e != nil && err == nil {
+					err = e
+				}
+			}
+		}
+	}
+
+	c.Lock()
+	if c.closed {
+		c.Unlock()
+		return err
+	}
+	c.closed = true
+	c.Unlock()
+	defer close(c.stop)
+
+	done := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// begin counts a message in flight and returns the channel to send it to, nil once
+// the producer is closed or before its first session. The count is taken under the
+// lock closing the producer so Close never waits while it grows.
+func (c *Producer) begin() chan MsgSend {
 	c.RLock()
-	dialer := c.dialer
-	c.RUnlock()
+	defer c.RUnlock()
+
+	if c.closed || c.pending == nil {
+		return nil
+	}
+	c.inflight.Add(1)
+	return c.pending
+}
 
-	if !dialer.IsConnected() {
+// SendingData queues the message to be published, or adds it to the sending que
+// when not connected. The errors to encode or compress the body are returned, the
+// message is then neither sent nor queued. It waits for the session to take the
+// message until the context is done or the producer is closed.
+func (c *Producer) SendingData(ctx context.Context, id string, key string, body interface{}, headers map[string]interface{}, cb database.ConsumerCallback) error {
+
+	if len(key) == 0 {
+		key = c.options.Exchange
+	}
+
+	if len(key) == 0 {
+		return dberrors.New("rabbitmq", c.tag, "push", errors.New("no queue to send the message to"))
+	}
+
+	if len(id) == 0 {
+		id = hash.CreateRandomId(10)
+	}
+
+	data, err := database.Encode(c.options.Encoding, id, body)
+	if err != nil {
+		return dberrors.New("rabbitmq", c.tag, "encode", err)
+	}
+
+	data, contentEncoding, err := compress.Compress(c.options.Compression, c.options.CompressionThreshold, data)
+	if err != nil {
+		return dberrors.New("rabbitmq", c.tag, "compress", err)
+	}
+
+	if !c.IsConnected() {
 		c.logger.Warning("Skip, not connected to rabbitmq server, add to sending que")
 		c.Lock()
 		c.pendingQue = append(c.pendingQue, PendingQue{
@@ -188,55 +314,40 @@ func (c *Producer) SendingData(id string, key string, body interface{}, headers
 			cb:     cb,
 		})
 		c.Unlock()
-		return
+		return nil
 	}
 
-	if len(key) == 0 {
-		key = c.options.Exchange
+	if headers == nil {
+		headers = make(map[string]interface{})
+	}
+	headers[database.HeaderContentType] = database.ContentTypeOf(c.options.Encoding)
+	if len(contentEncoding) != 0 {
+		headers[database.HeaderContentEncoding] = contentEncoding
 	}
 
-	if len(key) != 0 {
-		var data []byte
-
-		if len(id) == 0 {
-			id = hash.CreateRandomId(10)
-		}
-
-		if c.options.Encoding == database.EncodingBase64Gob {
-			bt := bytes.NewBuffer(nil)
-			defer bt.Reset()
-			if err := gob.NewEncoder(bt).Encode(body); err == nil {
-				data = []byte(base64.StdEncoding.EncodeToString(bt.Bytes()))
-			} else {
-				c.logger.Error("Failed to encode gob %s", err)
-			}
-		}
-
-		if c.options.Encoding == database.EncodingProto {
-			sendData := &databaseproto.SendData{
-				ID: id,
-			}
-			if val, ok := body.(proto.Message); ok {
-				if v, err := anypb.New(val); err == nil {
-					sendData.Data = v
-				}
-			}
-
-			if da, err := proto.Marshal(sendData); err == nil {
-				data = da
-			}
-
-		}
+	pending := c.begin()
+	if pending == nil {
+		return dberrors.New("rabbitmq", c.tag, "push", dberrors.ErrNotConnected)
+	}
 
-		if cb != nil {
-			if c.store != nil {
-				c.store.Put(id, cb)
-			}
+	if cb != nil {
+		if c.store != nil {
+			c.store.Put(id, cb)
 		}
+	}
 
-		c.Lock()
-		c.pending <- MsgSend{ID: id, Name: key, Data: data, Headers: headers}
-		c.Unlock()
+	select {
+	case pending <- MsgSend{ID: id, Name: key, Data: data, Headers: headers}:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-c.stop:
+		err = dberrors.ErrNotConnected
 	}
 
+	c.inflight.Done()
+	if cb != nil && c.store != nil {
+		c.store.Delete(id)
+	}
+	return dberrors.New("rabbitmq", c.tag, "push", err)
 }
@@ -0,0 +1,37 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+func TestProducerCloseWaitsInFlight(t *testing.T) {
+	c := NewProducer(nil, nil, database.RabbitMQProviderConfig{}, database.RabbitMQOptions{}, nil)
+	if c.begin() != nil {
+		t.Fatal("begin != nil before the first session")
+	}
+
+	c.pending = make(chan MsgSend, 1)
+	if c.begin() == nil {
+		t.Fatal("begin = nil with a session")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close = %v with a message in flight, want %v", err, context.DeadlineExceeded)
+	}
+
+	if c.begin() != nil {
+		t.Error("begin != nil after Close")
+	}
+
+	c.inflight.Done()
+	if err := c.Close(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
@@ -2,11 +2,20 @@ package rabbitmq
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/backoff"
+	"github.com/fajarardiyanto/flt-go-database/lib/events"
+	"github.com/fajarardiyanto/flt-go-database/lib/middleware"
+	"github.com/fajarardiyanto/flt-go-database/lib/retry"
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	gutils "github.com/fajarardiyanto/flt-go-utils/grpc"
 	"github.com/fajarardiyanto/flt-go-utils/hash"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,23 +24,30 @@ import (
 
 var storesCallback *Stores
 
+var errExchangeRequired = errors.New("exchange is required")
+
 func init() {
 	storesCallback = NewStore()
 }
 
 type RabbitMQ struct {
-	tag      string
-	id       string
-	log      logger.Logger
-	config   database.RabbitMQProviderConfig
-	producer *Producer
-	consumer map[string]*Consumer
-	dialer   *Dialer
+	tag       string
+	id        string
+	log       logger.Logger
+	config    database.RabbitMQProviderConfig
+	producer  *Producer
+	consumer  map[string]*Consumer
+	dialer    *Dialer
+	closed    bool
+	connected bool
+	attempt   int
+	ctx       context.Context
+	cancel    context.CancelFunc
 	sync.RWMutex
 }
 
 func NewRabbitMQ(tag string, lo logger.Logger, config database.RabbitMQProviderConfig) database.RabbitMQ {
-	id := hash.CreateSmallHash(10, config.Host,
+	id := hash.CreateSmallHash(10, strings.ToLower(tag), config.URL, config.Host,
 		strconv.Itoa(config.Port),
 		config.Username,
 		config.Password)
@@ -42,6 +58,7 @@ func NewRabbitMQ(tag string, lo logger.Logger, config database.RabbitMQProviderC
 
 	msq := &RabbitMQ{tag: strings.ToLower(tag), log: lo, config: config, id: id,
 		consumer: map[string]*Consumer{}}
+	msq.ctx, msq.cancel = context.WithCancel(context.Background())
 
 	if !config.DedicatedConnection && config.Enable {
 		msq.dialer = NewDialer(msq.tag, lo)
@@ -61,7 +78,20 @@ func (c *RabbitMQ) dial() {
 	c.dialer.Dial(context.Background(), c.config, c.onDialError, c.onConnected)
 }
 
+func (c *RabbitMQ) backoff() backoff.Policy {
+	if c.config.ReconnectDuration <= 0 {
+		c.config.ReconnectDuration = 5
+	}
+	return backoff.FromConfig(c.config.Backoff, c.config.ReconnectDuration, -1)
+}
+
 func (c *RabbitMQ) onConnected() {
+	c.Lock()
+	c.attempt = 0
+	c.connected = true
+	c.Unlock()
+	events.Connected("rabbitmq", c.tag)
+
 	if !c.config.DedicatedConnection {
 		time.Sleep(1 * time.Second)
 		c.RLock()
@@ -78,8 +108,11 @@ func (c *RabbitMQ) onConnected() {
 }
 
 func (c *RabbitMQ) onDialError(err error) {
-	if c.config.ReconnectDuration <= 0 {
-		c.config.ReconnectDuration = 5
+	c.RLock()
+	closed := c.closed
+	c.RUnlock()
+	if closed {
+		return
 	}
 
 	if !c.config.DedicatedConnection {
@@ -94,17 +127,48 @@ func (c *RabbitMQ) onDialError(err error) {
 			val.onError(nil)
 		}
 
-		reconnectDuration := time.Duration(c.config.ReconnectDuration) * time.Second
-		c.log.Warning("reconnecting in %s", reconnectDuration)
-		time.Sleep(reconnectDuration)
+		c.Lock()
+		c.attempt++
+		attempt := c.attempt
+		connected := c.connected
+		c.connected = false
+		c.Unlock()
+
+		if connected {
+			events.Disconnected("rabbitmq", c.tag, err)
+		}
+
+		policy := c.backoff()
+		if policy.Exhausted(attempt) {
+			c.log.Error("giving up reconnecting after %d attempts", attempt)
+			events.GaveUp("rabbitmq", c.tag, attempt, err)
+			return
+		}
+
+		delay := policy.Delay(attempt)
+		c.log.Warning("reconnecting in %s", delay)
+		events.Reconnecting("rabbitmq", c.tag, attempt, delay, err)
+		if err := backoff.Sleep(c.ctx, delay); err != nil {
+			return
+		}
 		c.log.Info("reconnecting now ...")
 		c.dial()
 	}
 }
 
 func (c *RabbitMQ) Producer(options database.RabbitMQOptions) {
+	if err := c.addProducer(options); err != nil {
+		c.log.Error(err)
+	}
+}
+
+func (c *RabbitMQ) addProducer(options database.RabbitMQOptions) error {
 	if !c.config.Enable {
-		c.log.Error("RabbitMQ is disabled").Quit()
+		return dberrors.New("rabbitmq", c.tag, "producer", dberrors.ErrDisabled)
+	}
+
+	if _, err := uri(c.ctx, c.config); err != nil {
+		return dberrors.New("rabbitmq", c.tag, "producer", err)
 	}
 
 	if len(options.ExchangeType) == 0 {
@@ -114,18 +178,66 @@ func (c *RabbitMQ) Producer(options database.RabbitMQOptions) {
 	c.Lock()
 	c.producer = NewProducer(c.log, c.dialer,
 		c.config, options, storesCallback)
+	c.producer.tag = c.tag
 	c.Unlock()
 
 	go c.producer.Init()
+
+	return nil
 }
 
 func (c *RabbitMQ) Consumer(options database.RabbitMQOptions, callback database.ConsumerCallback) {
+	if err := c.addConsumer(options, callback); err != nil {
+		c.log.Error(err)
+	}
+}
+
+// Handle consumes like Consumer, with ManualAck the delivery is acked once the handler
+// returns nil and nacked when it returns an error. With a retry policy the failed
+// messages are retried, then dead-lettered.
+func (c *RabbitMQ) Handle(options database.RabbitMQOptions, handler database.ConsumerHandler) {
+	if err := c.addHandler(options, handler); err != nil {
+		c.log.Error(err)
+	}
+}
+
+func (c *RabbitMQ) addHandler(options database.RabbitMQOptions, handler database.ConsumerHandler) error {
+	if options.Retry == nil {
+		return c.addConsumer(options, database.AckAfter(handler, options.RequeueOnError))
+	}
+
+	options.ManualAck = true
+	consumer, err := c.newConsumer(options, nil)
+	if err != nil {
+		return err
+	}
+	consumer.callback = database.AckAfter(retry.Handler(*options.Retry, consumer, handler), options.RequeueOnError)
+	c.startConsumer(consumer)
+
+	return nil
+}
+
+func (c *RabbitMQ) addConsumer(options database.RabbitMQOptions, callback database.ConsumerCallback) error {
+	consumer, err := c.newConsumer(options, callback)
+	if err != nil {
+		return err
+	}
+	c.startConsumer(consumer)
+
+	return nil
+}
+
+func (c *RabbitMQ) newConsumer(options database.RabbitMQOptions, callback database.ConsumerCallback) (*Consumer, error) {
 	if !c.config.Enable {
-		c.log.Error("RabbitMQ is disabled").Quit()
+		return nil, dberrors.New("rabbitmq", c.tag, "consumer", dberrors.ErrDisabled)
 	}
 
 	if len(options.Exchange) == 0 {
-		c.log.Error("Exchange is required").Quit()
+		return nil, dberrors.New("rabbitmq", c.tag, "consumer", errExchangeRequired)
+	}
+
+	if _, err := uri(c.ctx, c.config); err != nil {
+		return nil, dberrors.New("rabbitmq", c.tag, "consumer", err)
 	}
 
 	if len(options.ExchangeType) == 0 {
@@ -133,15 +245,91 @@ func (c *RabbitMQ) Consumer(options database.RabbitMQOptions, callback database.
 	}
 
 	consumer := NewConsumer(c.log, c.dialer, c.config, options, callback, storesCallback)
+	consumer.tag = c.tag
+	return consumer, nil
+}
+
+func (c *RabbitMQ) startConsumer(consumer *Consumer) {
+	consumer.callback = middleware.Chain(consumer.callback, consumer.options.Middlewares...)
+
 	c.Lock()
-	c.consumer[options.Exchange] = consumer
+	c.consumer[consumer.options.Exchange] = consumer
 	c.Unlock()
 
 	go consumer.Init()
+}
+
+func (c *RabbitMQ) Ping(ctx context.Context) error {
+	if !c.config.Enable {
+		return dberrors.New("rabbitmq", c.tag, "ping", dberrors.ErrDisabled)
+	}
+
+	c.RLock()
+	dialer := c.dialer
+	producer := c.producer
+	consm := c.consumer
+	c.RUnlock()
+
+	if !c.config.DedicatedConnection {
+		if dialer == nil || !dialer.IsConnected() {
+			return dberrors.New("rabbitmq", c.tag, "ping", dberrors.ErrNotConnected)
+		}
+		return nil
+	}
+
+	if producer == nil && len(consm) == 0 {
+		return dberrors.New("rabbitmq", c.tag, "ping", dberrors.ErrNotConnected)
+	}
+
+	if producer != nil && !producer.IsConnected() {
+		return dberrors.New("rabbitmq", c.tag, "ping", fmt.Errorf("producer %w", dberrors.ErrNotConnected))
+	}
+
+	for exchange, val := range consm {
+		if !val.IsConnected() {
+			return dberrors.New("rabbitmq", c.tag, "ping", fmt.Errorf("consumer %s %w", exchange, dberrors.ErrNotConnected))
+		}
+	}
 
+	return nil
 }
 
-func (c *RabbitMQ) Push(ctx context.Context, id, key string, body interface{}, cb database.ConsumerCallback) error {
+// Close stops the consumers and waits for their in-flight callbacks, flushes the
+// producer sending que then closes the connection. The client will not reconnect.
+func (c *RabbitMQ) Close(ctx context.Context) error {
+	c.Lock()
+	c.closed = true
+	c.cancel()
+	producer := c.producer
+	consm := c.consumer
+	dialer := c.dialer
+	c.Unlock()
+
+	var err error
+	for _, val := range consm {
+		if e := val.Close(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	if producer != nil {
+		if e := producer.Close(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	if dialer != nil {
+		if e := dialer.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	storesCallback.DeleteClient(c)
+
+	return err
+}
+
+func (c *RabbitMQ) Push(ctx context.Context, id, key string, body interface{}, cb database.ConsumerCallback) (err error) {
 	c.RLock()
 	producer := c.producer
 	c.RUnlock()
@@ -154,11 +342,19 @@ func (c *RabbitMQ) Push(ctx context.Context, id, key string, body interface{}, c
 		headers["uber-trace-id"] = meta.Get("uber-trace-id")
 	}
 
+	spanCtx, span := tracing.Start(ctx, key+" send", trace.SpanKindProducer,
+		semconv.MessagingSystemKey.String("rabbitmq"),
+		semconv.MessagingDestinationKey.String(key),
+		tracing.TagKey.String(c.tag))
+	defer func() {
+		tracing.End(span, err)
+	}()
+	tracing.Inject(spanCtx, headers)
+
 	if producer != nil {
 
 		if cb == nil {
-			producer.SendingData(id, key, body, headers, nil)
-			return nil
+			return producer.SendingData(spanCtx, id, key, body, headers, nil)
 		}
 
 		ctx, cancel := context.WithCancel(ctx)
@@ -180,11 +376,13 @@ func (c *RabbitMQ) Push(ctx context.Context, id, key string, body interface{}, c
 			},
 		}
 
-		producer.SendingData(id, key, body, headers, func(s database.Messages,
+		if err := producer.SendingData(spanCtx, id, key, body, headers, func(s database.Messages,
 			cid database.ConsumerCallbackIsDone) {
 			doneCtx = cid
 			cb(s, done)
-		})
+		}); err != nil {
+			return err
+		}
 
 		<-ctx.Done()
 
@@ -192,5 +390,5 @@ func (c *RabbitMQ) Push(ctx context.Context, id, key string, body interface{}, c
 
 	}
 
-	return fmt.Errorf("rabbitmq not ready")
+	return dberrors.New("rabbitmq", c.tag, "push", dberrors.ErrNotConnected)
 }
@@ -0,0 +1,81 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/retry"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"time"
+)
+
+// Retry publishes the message on the retry queue of the consumer for the delay, named
+// after the delay in milliseconds, which dead-letters it back to the queue of the
+// consumer once expired. Every message of a retry queue expires after the same
+// delay, none waits behind a longer one.
+func (c *Consumer) Retry(msg database.Messages, headers map[string]interface{}, delay time.Duration) error {
+	sub, err := c.channel("retry")
+	if err != nil {
+		return err
+	}
+
+	queue := c.queue()
+	retryQueue, _ := retry.Destinations(*c.options.Retry, queue)
+	ttl := delay.Milliseconds()
+	retryQueue = fmt.Sprintf("%s.%d", retryQueue, ttl)
+	if _, err := sub.QueueDeclare(retryQueue, c.options.Durable, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": queue,
+		"x-message-ttl":             ttl,
+	}); err != nil {
+		return dberrors.New("rabbitmq", c.tag, "retry", err)
+	}
+
+	if err := sub.PublishWithContext(context.Background(), "", retryQueue, false, false, c.publishing(msg, headers)); err != nil {
+		return dberrors.New("rabbitmq", c.tag, "retry", err)
+	}
+	return nil
+}
+
+// DeadLetter publishes the message on the dead letter queue of the consumer, named
+// after its queue like the retry queues.
+func (c *Consumer) DeadLetter(msg database.Messages, headers map[string]interface{}) error {
+	sub, err := c.channel("dead letter")
+	if err != nil {
+		return err
+	}
+
+	_, deadLetter := retry.Destinations(*c.options.Retry, c.queue())
+	if _, err := sub.QueueDeclare(deadLetter, c.options.Durable, false, false, false, nil); err != nil {
+		return dberrors.New("rabbitmq", c.tag, "dead letter", err)
+	}
+
+	if err := sub.PublishWithContext(context.Background(), "", deadLetter, false, false, c.publishing(msg, headers)); err != nil {
+		return dberrors.New("rabbitmq", c.tag, "dead letter", err)
+	}
+	c.logger.Warning("[%s] message %s dead-lettered to %s", c.options.Exchange, msg.ID(), deadLetter)
+	return nil
+}
+
+func (c *Consumer) channel(op string) (*amqp.Channel, error) {
+	c.RLock()
+	session := c.session
+	c.RUnlock()
+
+	if session == nil || session.Channel == nil || session.Channel.IsClosed() {
+		return nil, dberrors.New("rabbitmq", c.tag, op, dberrors.ErrNotConnected)
+	}
+	return session.Channel, nil
+}
+
+func (c *Consumer) publishing(msg database.Messages, headers map[string]interface{}) amqp.Publishing {
+	contentType, _ := headers[database.HeaderContentType].(string)
+	return amqp.Publishing{
+		Headers:     headers,
+		ContentType: contentType,
+		MessageId:   msg.ID(),
+		Timestamp:   time.Now(),
+		Body:        msg.Body(),
+	}
+}
@@ -27,6 +27,15 @@ func (c *Stores) StoreClient(client *RabbitMQ) {
 	c.Unlock()
 }
 
+func (c *Stores) DeleteClient(client *RabbitMQ) {
+	c.Lock()
+	delete(c.client, client.id)
+	if c.clienttags[client.tag] == client.id {
+		delete(c.clienttags, client.tag)
+	}
+	c.Unlock()
+}
+
 func (c *Stores) LoadClient(id string) (client *RabbitMQ, ok bool) {
 	c.RLock()
 	client, ok = c.client[id]
@@ -0,0 +1,38 @@
+package rabbitmq
+
+import (
+	"context"
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	databasev2 "github.com/fajarardiyanto/flt-go-database/interfaces/v2"
+)
+
+type clientV2 struct {
+	*RabbitMQ
+}
+
+// V2 returns the client with the context-first interface, Consumer and Producer
+// return the errors the v1 methods only log.
+func (c *RabbitMQ) V2() databasev2.RabbitMQ {
+	return clientV2{c}
+}
+
+func (v clientV2) Consumer(ctx context.Context, options database.RabbitMQOptions, callback database.ConsumerCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addConsumer(options, callback)
+}
+
+func (v clientV2) Handle(ctx context.Context, options database.RabbitMQOptions, handler database.ConsumerHandler) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addHandler(options, handler)
+}
+
+func (v clientV2) Producer(ctx context.Context, options database.RabbitMQOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return v.addProducer(options)
+}
@@ -0,0 +1,110 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+// Mask replaces every redacted secret.
+const Mask = "xxxxx"
+
+// DefaultPayloadLimit is the number of bytes logged by PayloadTruncate.
+const DefaultPayloadLimit = 256
+
+var (
+	mode  = database.PayloadHash
+	limit = DefaultPayloadLimit
+	mutex sync.RWMutex
+
+	// the password runs to the last @ of the authority, it may hold a / or an @
+	userinfo   = regexp.MustCompile(`(//[^:/@?#]*:)[^?#]*@`)
+	kvPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+)
+
+// SetPayloadLogging sets how the bodies are logged by every client, the limit only
+// applies to PayloadTruncate and falls back to DefaultPayloadLimit when not positive.
+func SetPayloadLogging(m database.PayloadLogging, l int) {
+	if l <= 0 {
+		l = DefaultPayloadLimit
+	}
+
+	mutex.Lock()
+	mode, limit = m, l
+	mutex.Unlock()
+}
+
+// URL masks the password of the URL, the URLs the standard parser refuses are
+// masked textually.
+func URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return userinfo.ReplaceAllString(raw, "${1}"+Mask+"@")
+	}
+	if u.User == nil {
+		return raw
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), Mask)
+	}
+	return u.String()
+}
+
+// DSN masks the password of a connection string, in the URL, the MySQL
+// user:password@tcp(host) or the key/value password=secret form.
+func DSN(dsn string) string {
+	if userinfo.MatchString(dsn) {
+		return URL(dsn)
+	}
+	if kvPassword.MatchString(dsn) {
+		return kvPassword.ReplaceAllString(dsn, "${1}"+Mask)
+	}
+	return mysqlDSN(dsn)
+}
+
+// mysqlDSN masks the password of user:password@tcp(host)/db, the userinfo ends at
+// the last @ before the last /, as the driver parses it.
+func mysqlDSN(dsn string) string {
+	end := strings.LastIndex(dsn, "/")
+	if end < 0 {
+		end = len(dsn)
+	}
+	at := strings.LastIndex(dsn[:end], "@")
+	if at < 0 {
+		return dsn
+	}
+	colon := strings.Index(dsn[:at], ":")
+	if colon < 0 {
+		return dsn
+	}
+	return dsn[:colon+1] + Mask + dsn[at:]
+}
+
+// Payload returns the loggable form of the body according to the payload logging.
+func Payload(data []byte) string {
+	mutex.RLock()
+	m, l := mode, limit
+	mutex.RUnlock()
+
+	switch m {
+	case database.PayloadFull:
+		return string(data)
+	case database.PayloadTruncate:
+		if len(data) <= l {
+			return string(data)
+		}
+		return fmt.Sprintf("%s... (%d bytes)", data[:l], len(data))
+	case database.PayloadOmit:
+		return fmt.Sprintf("(%d bytes)", len(data))
+	default:
+		sum := sha256.Sum256(data)
+		return fmt.Sprintf("(%d bytes, sha256:%s)", len(data), hex.EncodeToString(sum[:6]))
+	}
+}
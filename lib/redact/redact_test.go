@@ -0,0 +1,39 @@
+package redact
+
+import "testing"
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		raw, want string
+	}{
+		{"amqp://user:secret@h:5672/vhost", "amqp://user:xxxxx@h:5672/vhost"},
+		{"amqp://user:pa/ss@h:5672/", "amqp://user:xxxxx@h:5672/"},
+		{"mongodb://u:secret@h1,h2/?authSource=admin", "mongodb://u:xxxxx@h1,h2/?authSource=admin"},
+		{"redis://:secret@h:6379/0", "redis://:xxxxx@h:6379/0"},
+		{"http://h:9200", "http://h:9200"},
+		{"http://h:9200/p@x", "http://h:9200/p@x"},
+	}
+
+	for _, tt := range tests {
+		if got := URL(tt.raw); got != tt.want {
+			t.Errorf("URL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		dsn, want string
+	}{
+		{"root:pa/s@ss@tcp(127.0.0.1:3306)/db?parseTime=true", "root:xxxxx@tcp(127.0.0.1:3306)/db?parseTime=true"},
+		{"root@tcp(127.0.0.1:3306)/db", "root@tcp(127.0.0.1:3306)/db"},
+		{"host=h password='a b' user=u", "host=h password=xxxxx user=u"},
+		{"postgres://u:p/w@h/db?sslmode=disable", "postgres://u:xxxxx@h/db?sslmode=disable"},
+	}
+
+	for _, tt := range tests {
+		if got := DSN(tt.dsn); got != tt.want {
+			t.Errorf("DSN(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
@@ -3,104 +3,216 @@ package redis
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
+	"sync"
 	"time"
 
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/backoff"
+	"github.com/fajarardiyanto/flt-go-database/lib/secret"
+	"github.com/fajarardiyanto/flt-go-database/lib/tlsutil"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	"github.com/go-redis/redis/v8"
 )
 
 type service struct {
-	reconnectAt int
-	db          *redis.Client
-	log         logger.Logger
-	lastTimeout time.Duration
-	pool        *redis.PoolStats
-	config      interfaces.RedisProviderConfig
+	tag    string
+	db     *redis.Client
+	log    logger.Logger
+	pool   *redis.PoolStats
+	config interfaces.RedisProviderConfig
+	conn   *backoff.Connection
+	sync.RWMutex
 }
 
 func NewRedis(log logger.Logger, config interfaces.RedisProviderConfig) interfaces.Redis {
-	log.Debug("ElasticSearch Client %s:%d has been registered", config.Host, config.Port)
-	return &service{
+	return NewRedisWithTag("default", log, config)
+}
+
+func NewRedisWithTag(tag string, log logger.Logger, config interfaces.RedisProviderConfig) interfaces.Redis {
+	log.Debug("Redis Client %s:%d has been registered", config.Host, config.Port)
+	s := &service{
+		tag:    strings.ToLower(tag),
 		config: config,
 		log:    log,
 	}
+	s.conn = backoff.NewConnection("redis", s.tag, log, config.Backoff, config.StartInterval, config.MaxError, config.AutoReconnect)
+	s.conn.Connect, s.conn.Ping = s.connect, s.Ping
+	return s
 }
 
-func (s *service) Init() (err error) {
+// Init connects the client, a disabled provider is not an error.
+func (s *service) Init() error {
+	if err := s.start(context.Background()); err != nil && !dberrors.Is(err, dberrors.ErrDisabled) {
+		return err
+	}
+	return nil
+}
+
+// start connects the client, then watches it with AutoReconnect.
+func (s *service) start(ctx context.Context) (err error) {
 	if !s.config.Enable {
 		msg := "aborted, redis database not enable in config, double check configuration again"
 		s.log.Error(msg)
-		return nil
+		return dberrors.New("redis", s.tag, "connect", dberrors.ErrDisabled)
 	}
 
-	s.log.Debug("Connecting to redis database server %s:%d", s.config.Host, s.config.Port)
+	if _, err := s.options(ctx); err != nil {
+		s.log.Error(err)
+		return err
+	}
 
-	redisAddress := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-	s.db = redis.NewClient(&redis.Options{
-		Addr:     redisAddress,
-		Password: s.config.Password,
-		DB:       0,
-	})
+	return s.conn.Start(ctx)
+}
+
+// options parses the URL of the config, redis:// or rediss:// for TLS, when set,
+// otherwise builds the options from Host, Port and Password.
+// A Password resolved by a secret provider is resolved again each time a connection
+// of the pool is opened.
+func (s *service) options(ctx context.Context) (opts *redis.Options, err error) {
+	if len(s.config.URL) == 0 {
+		password, err := secret.Resolve(ctx, s.config.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = &redis.Options{
+			Addr:     fmt.Sprintf("%s:%d", s.config.Host, s.config.Port),
+			Password: password,
+			DB:       0,
+		}
+
+		if password != s.config.Password {
+			opts.Password = ""
+			opts.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+				password, err := secret.Resolve(ctx, s.config.Password)
+				if err != nil {
+					return err
+				}
+				return cn.Auth(ctx, password).Err()
+			}
+		}
+	} else {
+		addr, err := secret.Resolve(ctx, s.config.URL)
+		if err != nil {
+			return nil, err
+		}
+		if opts, err = redis.ParseURL(addr); err != nil {
+			return nil, fmt.Errorf("invalid redis url: %w", err)
+		}
+	}
+
+	if s.config.TLS.Enable {
+		if opts.TLSConfig, err = tlsutil.Config(s.config.TLS); err != nil {
+			return nil, err
+		}
+		if len(opts.TLSConfig.ServerName) == 0 {
+			opts.TLSConfig.ServerName, _, _ = net.SplitHostPort(opts.Addr)
+		}
+	}
+
+	return opts, nil
+}
+
+func (s *service) connect(ctx context.Context) (err error) {
+	opts, err := s.options(ctx)
+	if err != nil {
+		return err
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	s.log.Debug("Connecting to redis database server %s", opts.Addr)
+
+	db := redis.NewClient(opts)
+	db.AddHook(tracingHook{tag: s.tag})
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	if err = s.db.Info(ctx).Err(); err != nil {
-		return s.OnInitError(err)
+	if err = db.Info(ctx).Err(); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	s.Lock()
+	previous := s.db
+	s.db = db
+	s.Unlock()
+
+	if previous != nil {
+		_ = previous.Close()
 	}
 
-	s.log.Info("Success to connect redis %s:%d", s.config.Host, s.config.Port)
+	s.log.Info("Success to connect redis %s", opts.Addr)
 
 	return nil
 }
 
-func (s *service) OnInitError(e error) (err error) {
-	if s.reconnectAt >= s.config.MaxError {
-		return e
-	}
+// client returns the connected client, nil before Init and after Close.
+func (s *service) client() *redis.Client {
+	s.RLock()
+	defer s.RUnlock()
+	return s.db
+}
 
-	s.reconnectAt++
-	if !s.config.AutoReconnect {
-		return e
+func (s *service) Ping(ctx context.Context) error {
+	db := s.client()
+	if db == nil {
+		return dberrors.New("redis", s.tag, "ping", dberrors.ErrNotConnected)
 	}
 
-	var ttm = 2
-	if s.lastTimeout.Seconds() == 0 {
-		ttm = 1
-		s.lastTimeout = time.Duration(s.config.StartInterval) * time.Second
-	}
+	return db.Ping(ctx).Err()
+}
+
+func (s *service) Close(ctx context.Context) error {
+	s.Lock()
+	db := s.db
+	s.db = nil
+	s.Unlock()
+	s.conn.Stop()
 
-	s.lastTimeout = time.Duration(int(s.lastTimeout.Seconds())*ttm) * time.Second
-	s.log.Error(e)
-	s.log.Warning("Reconnecting in %s", s.lastTimeout)
-	time.Sleep(s.lastTimeout)
+	if db == nil {
+		return nil
+	}
 
-	return s.Init()
+	return db.Close()
 }
 
 func (s *service) GetPool() *redis.PoolStats {
+	db := s.client()
+
+	s.Lock()
+	defer s.Unlock()
+	if db != nil {
+		s.pool = db.PoolStats()
+	}
 	return s.pool
 }
 
 func (s *service) Set(ctx context.Context, key string, val interface{}, ttl time.Duration) error {
-	if s.pool = s.db.PoolStats(); s.pool == nil {
-		return fmt.Errorf("redis instance not available or not connected")
+	db := s.client()
+	if db == nil {
+		return dberrors.New("redis", s.tag, "set", dberrors.ErrNotConnected)
 	}
 
-	return s.db.Set(ctx, key, val, ttl).Err()
+	if err := db.Set(ctx, key, val, ttl).Err(); err != nil {
+		return dberrors.New("redis", s.tag, "set", err)
+	}
+	return nil
 }
 
 func (s *service) Get(ctx context.Context, key string) (string, error) {
-	if s.pool = s.db.PoolStats(); s.pool == nil {
-		return "", fmt.Errorf("redis instance not available or not connected")
+	db := s.client()
+	if db == nil {
+		return "", dberrors.New("redis", s.tag, "get", dberrors.ErrNotConnected)
 	}
 
-	val, err := s.db.Get(ctx, key).Result()
+	val, err := db.Get(ctx, key).Result()
 	if err == redis.Nil {
-		return "", fmt.Errorf("redis keys not found")
+		return "", dberrors.New("redis", s.tag, "get", fmt.Errorf("%w: key %s", dberrors.ErrNotFound, key))
 	} else if err != nil {
-		return "", err
+		return "", dberrors.New("redis", s.tag, "get", err)
 	}
 
 	return val, err
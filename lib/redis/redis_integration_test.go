@@ -0,0 +1,34 @@
+//go:build integration
+// +build integration
+
+package redis_test
+
+import (
+	"os"
+	"testing"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/interfaces/interfacestest"
+	"github.com/fajarardiyanto/flt-go-database/lib/redis"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+// TestRedis runs the suite against the server of INTEGRATION_REDIS_URL, e.g.
+// redis://127.0.0.1:6379/0.
+func TestRedis(t *testing.T) {
+	url := os.Getenv("INTEGRATION_REDIS_URL")
+	if len(url) == 0 {
+		t.Skip("INTEGRATION_REDIS_URL not set")
+	}
+
+	logger := log.NewLib()
+	logger.Init("redis integration")
+
+	interfacestest.RunRedisSuite(t, func(t *testing.T) database.Redis {
+		client := redis.NewRedisWithTag(t.Name(), logger, database.RedisProviderConfig{Enable: true, URL: url})
+		if err := client.Init(); err != nil {
+			t.Fatalf("Init: %v", err)
+		}
+		return client
+	})
+}
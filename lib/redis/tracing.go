@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingHook creates a client span around every redis command and pipeline.
+type tracingHook struct {
+	tag string
+}
+
+func (h tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = tracing.Start(ctx, "redis."+cmd.Name(), trace.SpanKindClient,
+		semconv.DBSystemRedis,
+		semconv.DBOperationKey.String(cmd.Name()),
+		tracing.TagKey.String(h.tag))
+	return ctx, nil
+}
+
+func (h tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	err := cmd.Err()
+	if err == redis.Nil {
+		err = nil
+	}
+	tracing.End(trace.SpanFromContext(ctx), err)
+	return nil
+}
+
+func (h tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	names := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name())
+	}
+
+	ctx, _ = tracing.Start(ctx, "redis.pipeline", trace.SpanKindClient,
+		semconv.DBSystemRedis,
+		semconv.DBOperationKey.String(strings.Join(names, " ")),
+		tracing.TagKey.String(h.tag))
+	return ctx, nil
+}
+
+func (h tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if e := cmd.Err(); e != nil && e != redis.Nil {
+			err = e
+			break
+		}
+	}
+	tracing.End(trace.SpanFromContext(ctx), err)
+	return nil
+}
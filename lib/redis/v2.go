@@ -0,0 +1,21 @@
+package redis
+
+import (
+	"context"
+
+	databasev2 "github.com/fajarardiyanto/flt-go-database/interfaces/v2"
+)
+
+type clientV2 struct {
+	*service
+}
+
+// V2 returns the client with the context-first interface, Connect reports a
+// disabled provider as ErrDisabled.
+func (s *service) V2() databasev2.Redis {
+	return clientV2{s}
+}
+
+func (v clientV2) Connect(ctx context.Context) error {
+	return v.start(ctx)
+}
@@ -0,0 +1,131 @@
+package lib
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+// DefaultTag is the tag used to register clients loaded without a tag.
+const DefaultTag = "default"
+
+type registry struct {
+	sql           map[string]database.SQL
+	redis         map[string]database.Redis
+	mongo         map[string]database.Mongo
+	elasticsearch map[string]database.ElasticSearch
+	rabbitmq      map[string]database.RabbitMQ
+	kafka         map[string]database.Kafka
+}
+
+func newRegistry() registry {
+	return registry{
+		sql:           make(map[string]database.SQL),
+		redis:         make(map[string]database.Redis),
+		mongo:         make(map[string]database.Mongo),
+		elasticsearch: make(map[string]database.ElasticSearch),
+		rabbitmq:      make(map[string]database.RabbitMQ),
+		kafka:         make(map[string]database.Kafka),
+	}
+}
+
+type client interface {
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+type registered struct {
+	provider string
+	tag      string
+	client   client
+}
+
+func (m *Modules) clients() []registered {
+	m.RLock()
+	defer m.RUnlock()
+
+	var clients []registered
+	for tag, val := range m.registry.sql {
+		clients = append(clients, registered{"sql", tag, val})
+	}
+	for tag, val := range m.registry.redis {
+		clients = append(clients, registered{"redis", tag, val})
+	}
+	for tag, val := range m.registry.mongo {
+		clients = append(clients, registered{"mongo", tag, val})
+	}
+	for tag, val := range m.registry.elasticsearch {
+		clients = append(clients, registered{"elasticsearch", tag, val})
+	}
+	for tag, val := range m.registry.rabbitmq {
+		clients = append(clients, registered{"rabbitmq", tag, val})
+	}
+	for tag, val := range m.registry.kafka {
+		clients = append(clients, registered{"kafka", tag, val})
+	}
+
+	return clients
+}
+
+// List returns the provider and tag of every registered client, sorted by provider
+// then tag.
+func (m *Modules) List() []database.ClientInfo {
+	clients := m.clients()
+	list := make([]database.ClientInfo, 0, len(clients))
+	for _, val := range clients {
+		list = append(list, database.ClientInfo{Provider: val.provider, Tag: val.tag})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Provider != list[j].Provider {
+			return list[i].Provider < list[j].Provider
+		}
+		return list[i].Tag < list[j].Tag
+	})
+
+	return list
+}
+
+func (m *Modules) GetSQL(tag string) (database.SQL, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	client, ok := m.registry.sql[strings.ToLower(tag)]
+	return client, ok
+}
+
+func (m *Modules) GetRedis(tag string) (database.Redis, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	client, ok := m.registry.redis[strings.ToLower(tag)]
+	return client, ok
+}
+
+func (m *Modules) GetMongo(tag string) (database.Mongo, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	client, ok := m.registry.mongo[strings.ToLower(tag)]
+	return client, ok
+}
+
+func (m *Modules) GetElasticSearch(tag string) (database.ElasticSearch, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	client, ok := m.registry.elasticsearch[strings.ToLower(tag)]
+	return client, ok
+}
+
+func (m *Modules) GetRabbitMQ(tag string) (database.RabbitMQ, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	client, ok := m.registry.rabbitmq[strings.ToLower(tag)]
+	return client, ok
+}
+
+func (m *Modules) GetKafka(tag string) (database.Kafka, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	client, ok := m.registry.kafka[strings.ToLower(tag)]
+	return client, ok
+}
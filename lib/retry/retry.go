@@ -0,0 +1,129 @@
+package retry
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/backoff"
+)
+
+// Publisher sends a failed message to the retry destination of its consumer, to be
+// handled again once the delay is over, or to its dead letter destination. The
+// headers are the ones of the message with the retry headers set.
+type Publisher interface {
+	Retry(msg database.Messages, headers map[string]interface{}, delay time.Duration) error
+	DeadLetter(msg database.Messages, headers map[string]interface{}) error
+}
+
+// Handler returns the handler retrying the messages the handler failed, the message
+// is handled again or dead-lettered once published and the handler returns nil. The
+// error of the handler is returned when the publisher fails, the delivery is then
+// nacked. The delays are not randomized, the brokers keep a retry destination per
+// delay.
+func Handler(policy database.RetryPolicy, publisher Publisher, handler database.ConsumerHandler) database.ConsumerHandler {
+	config := policy.Backoff
+	config.Jitter = -1
+	delays := backoff.FromConfig(config, 0, -1)
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = database.DefaultMaxAttempts
+	}
+
+	return func(msg database.Messages, done database.ConsumerCallbackIsDone) error {
+		err := call(handler, msg, done)
+		if err == nil {
+			return nil
+		}
+
+		attempt := Attempt(msg.Headers())
+		headers := make(map[string]interface{}, len(msg.Headers())+2)
+		for k, v := range msg.Headers() {
+			headers[k] = v
+		}
+		// the body is republished decompressed
+		delete(headers, database.HeaderContentEncoding)
+
+		if attempt < maxAttempts {
+			headers[database.HeaderRetryAttempt] = strconv.Itoa(attempt + 1)
+			if e := publisher.Retry(msg, headers, delays.Delay(attempt)); e != nil {
+				return fmt.Errorf("%w, retry failed: %v", err, e)
+			}
+			return nil
+		}
+
+		headers[database.HeaderRetryAttempt] = strconv.Itoa(attempt)
+		headers[database.HeaderDeadLetterReason] = err.Error()
+		if e := publisher.DeadLetter(msg, headers); e != nil {
+			return fmt.Errorf("%w, dead letter failed: %v", err, e)
+		}
+		return nil
+	}
+}
+
+// call returns the panic of the handler as its error, the message is retried then
+// dead-lettered like a failed one instead of reaching the Recover middleware.
+func call(handler database.ConsumerHandler, msg database.Messages, done database.ConsumerCallbackIsDone) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(msg, done)
+}
+
+// Attempt returns the attempt of a received message, 1 for a message never retried.
+func Attempt(headers map[string]interface{}) int {
+	attempt := 0
+	switch val := headers[database.HeaderRetryAttempt].(type) {
+	case string:
+		attempt, _ = strconv.Atoi(val)
+	case []byte:
+		attempt, _ = strconv.Atoi(string(val))
+	case int:
+		attempt = val
+	case int32:
+		attempt = int(val)
+	case int64:
+		attempt = int(val)
+	}
+
+	if attempt < 1 {
+		return 1
+	}
+	return attempt
+}
+
+// NotBefore returns when a retried message may be handled, zero when it may be
+// handled right away.
+func NotBefore(headers map[string]interface{}) time.Time {
+	var raw string
+	switch val := headers[database.HeaderRetryNotBefore].(type) {
+	case string:
+		raw = val
+	case []byte:
+		raw = string(val)
+	default:
+		return time.Time{}
+	}
+
+	ms, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// Destinations returns the retry and dead letter destinations of the policy for the
+// destination of a consumer.
+func Destinations(policy database.RetryPolicy, destination string) (string, string) {
+	retry, deadLetter := policy.Retry, policy.DeadLetter
+	if len(retry) == 0 {
+		retry = destination + ".retry"
+	}
+	if len(deadLetter) == 0 {
+		deadLetter = destination + ".dlq"
+	}
+	return retry, deadLetter
+}
@@ -0,0 +1,72 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+type recordingPublisher struct {
+	delays  []time.Duration
+	reasons []interface{}
+}
+
+func (p *recordingPublisher) Retry(msg database.Messages, headers map[string]interface{}, delay time.Duration) error {
+	p.delays = append(p.delays, delay)
+	return nil
+}
+
+func (p *recordingPublisher) DeadLetter(msg database.Messages, headers map[string]interface{}) error {
+	p.reasons = append(p.reasons, headers[database.HeaderDeadLetterReason])
+	return nil
+}
+
+func TestHandlerDelaysWithoutJitter(t *testing.T) {
+	publisher := &recordingPublisher{}
+	handler := Handler(database.RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     database.BackoffConfig{InitialInterval: 1000, Jitter: 0.5},
+	}, publisher, func(msg database.Messages, done database.ConsumerCallbackIsDone) error {
+		return errors.New("failing")
+	})
+
+	for i := 0; i < 10; i++ {
+		msg := database.NewMessage(nil, "orders", "", database.EncodingJSON, database.MessageInfo{
+			Headers: map[string]interface{}{database.HeaderRetryAttempt: "2"},
+		})
+		if err := handler(msg, database.ConsumerCallbackIsDone{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, delay := range publisher.delays {
+		if delay != publisher.delays[0] {
+			t.Fatalf("delays = %v, want the same delay for the same attempt", publisher.delays)
+		}
+	}
+}
+
+func TestHandlerRetriesPanics(t *testing.T) {
+	publisher := &recordingPublisher{}
+	handler := Handler(database.RetryPolicy{MaxAttempts: 2}, publisher, func(msg database.Messages, done database.ConsumerCallbackIsDone) error {
+		panic("broken")
+	})
+
+	for _, attempt := range []string{"1", "2"} {
+		msg := database.NewMessage(nil, "orders", "", database.EncodingJSON, database.MessageInfo{
+			Headers: map[string]interface{}{database.HeaderRetryAttempt: attempt},
+		})
+		if err := handler(msg, database.ConsumerCallbackIsDone{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(publisher.delays) != 1 {
+		t.Errorf("retries = %d, want 1", len(publisher.delays))
+	}
+	if len(publisher.reasons) != 1 || publisher.reasons[0] != "panic: broken" {
+		t.Errorf("dead letter reasons = %v, want [panic: broken]", publisher.reasons)
+	}
+}
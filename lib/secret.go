@@ -0,0 +1,13 @@
+package lib
+
+import (
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/secret"
+)
+
+// RegisterSecretProvider resolves the config values written scheme:reference with
+// the provider for every client of the process, the env and file schemes are built
+// in.
+func RegisterSecretProvider(scheme string, provider database.SecretProvider) {
+	secret.Register(scheme, provider)
+}
@@ -0,0 +1,82 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+var (
+	providers = map[string]database.SecretProvider{
+		"env":  database.SecretProviderFunc(fromEnv),
+		"file": database.SecretProviderFunc(fromFile),
+	}
+	mutex sync.RWMutex
+)
+
+// Register adds the provider of the scheme, replacing the previous one. The env
+// and file schemes are registered by default.
+func Register(scheme string, provider database.SecretProvider) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if provider == nil {
+		delete(providers, scheme)
+		return
+	}
+	providers[scheme] = provider
+}
+
+// Resolve returns the secret referenced by the value when it starts with a
+// registered scheme followed by a colon, otherwise the value itself.
+func Resolve(ctx context.Context, value string) (string, error) {
+	idx := strings.Index(value, ":")
+	if idx <= 0 {
+		return value, nil
+	}
+
+	mutex.RLock()
+	provider, ok := providers[value[:idx]]
+	mutex.RUnlock()
+	if !ok {
+		return value, nil
+	}
+
+	resolved, err := provider.Resolve(ctx, value[idx+1:])
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret: %w", value[:idx], err)
+	}
+	return resolved, nil
+}
+
+// ResolveAll resolves every value in place.
+func ResolveAll(ctx context.Context, values ...*string) error {
+	for _, value := range values {
+		resolved, err := Resolve(ctx, *value)
+		if err != nil {
+			return err
+		}
+		*value = resolved
+	}
+	return nil
+}
+
+func fromEnv(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func fromFile(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
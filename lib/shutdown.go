@@ -0,0 +1,59 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Shutdown closes every registered client within the deadline of the context. The
+// brokers are closed first so the in-flight callbacks can still use the data stores,
+// then the data stores are closed.
+func (m *Modules) Shutdown(ctx context.Context) error {
+	var brokers, stores []registered
+	for _, val := range m.clients() {
+		switch val.provider {
+		case "rabbitmq", "kafka":
+			brokers = append(brokers, val)
+		default:
+			stores = append(stores, val)
+		}
+	}
+
+	err := m.closeAll(ctx, brokers)
+	if e := m.closeAll(ctx, stores); e != nil && err == nil {
+		err = e
+	}
+
+	return err
+}
+
+func (m *Modules) closeAll(ctx context.Context, clients []registered) error {
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		err   error
+	)
+
+	for _, val := range clients {
+		wg.Add(1)
+		go func(val registered) {
+			defer wg.Done()
+
+			if e := val.client.Close(ctx); e != nil {
+				if m.logging != nil {
+					m.logging.Error("Failed to close %s %s: %s", val.provider, val.tag, e)
+				}
+
+				mutex.Lock()
+				if err == nil {
+					err = fmt.Errorf("%s %q: %w", val.provider, val.tag, e)
+				}
+				mutex.Unlock()
+			}
+		}(val)
+	}
+
+	wg.Wait()
+	return err
+}
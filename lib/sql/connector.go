@@ -0,0 +1,40 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+// connector opens every connection of the pool with the credentials resolved again,
+// a rotated secret is picked up by the next connection without restarting.
+type connector struct {
+	sql    *SQL
+	driver driver.Driver
+	dsn    func(config interfaces.SQLConfig) string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	config, err := c.sql.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := c.dsn(config)
+	if d, ok := c.driver.(driver.DriverContext); ok {
+		opened, err := d.OpenConnector(dsn)
+		if err != nil {
+			return nil, redactError(err, dsn)
+		}
+		conn, err := opened.Connect(ctx)
+		return conn, redactError(err, dsn)
+	}
+
+	conn, err := c.driver.Open(dsn)
+	return conn, redactError(err, dsn)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,40 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+type recordingDriver struct {
+	dsn []string
+}
+
+func (d *recordingDriver) Open(dsn string) (driver.Conn, error) {
+	d.dsn = append(d.dsn, dsn)
+	return nil, errors.New("not connected")
+}
+
+func TestConnectorResolvesEveryConnection(t *testing.T) {
+	t.Setenv("FLT_SQL_TEST_PASSWORD", "first")
+
+	d := &recordingDriver{}
+	c := &connector{
+		sql:    &SQL{config: interfaces.SQLConfig{Username: "root", Password: "env:FLT_SQL_TEST_PASSWORD"}},
+		driver: d,
+		dsn: func(config interfaces.SQLConfig) string {
+			return config.Username + ":" + config.Password
+		},
+	}
+
+	_, _ = c.Connect(context.Background())
+	t.Setenv("FLT_SQL_TEST_PASSWORD", "rotated")
+	_, _ = c.Connect(context.Background())
+
+	if len(d.dsn) != 2 || d.dsn[0] != "root:first" || d.dsn[1] != "root:rotated" {
+		t.Errorf("dsn = %q, want [root:first root:rotated]", d.dsn)
+	}
+}
@@ -2,58 +2,58 @@ package sql
 
 import (
 	"context"
+	"errors"
 	"strings"
-	"time"
+
+	"github.com/fajarardiyanto/flt-go-database/lib/redact"
+	"gorm.io/gorm"
 )
 
-func (c *SQL) OnError(e error) (err error) {
-	if c.config.MaxError == 0 {
-		c.config.MaxError = 5
-	}
+func (c *SQL) Close(ctx context.Context) error {
+	c.Lock()
+	db := c.db
+	c.db = nil
+	c.Unlock()
+	c.conn.Stop()
 
-	if c.reconnectAt >= c.config.MaxError {
-		return e
+	if db == nil {
+		return nil
 	}
 
-	c.reconnectAt++
-	if !c.config.AutoReconnect {
-		return e
-	}
-
-	var ttm = 2
-	if c.lastTimeout.Seconds() == 0 {
-		ttm = 1
-		c.lastTimeout = time.Duration(c.config.StartInterval) * time.Second
+	conn, err := db.DB()
+	if err != nil {
+		return err
 	}
+	return conn.Close()
+}
 
-	c.lastTimeout = time.Duration(int(c.lastTimeout.Seconds())*ttm) * time.Second
-	c.log.Error(e)
-	c.log.Warning("Reconnecting in %s", c.lastTimeout)
-	time.Sleep(c.lastTimeout)
+// setDB replaces the session, closing the pool of the previous one.
+func (c *SQL) setDB(db *gorm.DB) {
+	c.Lock()
+	previous := c.db
+	c.db = db
+	c.Unlock()
 
-	return c.MySQL()
+	if previous != nil && previous != db {
+		release(previous)
+	}
 }
 
-func (c *SQL) Close() {
-	defer func() {
-		if !c.config.AutoReconnect {
-			c.db = nil
-			c = nil
-		}
-	}()
-
-	if sql, err := c.db.DB(); err == nil {
-		if err := sql.Close(); err != nil {
-			return
-		}
-		if _, err := sql.Conn(context.Background()); err != nil {
-			if strings.Contains(err.Error(), "database is closed") {
-				c.log.Debug("Database has been closed to %s@%s:%d",
-					c.config.Username,
-					c.config.Host, c.config.Port)
-			}
-		}
+// release closes the pool of a session which failed to connect or was replaced.
+func release(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	if conn, err := db.DB(); err == nil {
+		_ = conn.Close()
+	}
+}
 
+// redactError masks the password of the connection string when the driver quotes it
+// in the error.
+func redactError(err error, address string) error {
+	if err == nil || !strings.Contains(err.Error(), address) {
+		return err
 	}
-	return
+	return errors.New(strings.ReplaceAll(err.Error(), address, redact.DSN(address)))
 }
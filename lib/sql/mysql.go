@@ -1,27 +1,54 @@
 package sql
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/redact"
+	"github.com/fajarardiyanto/flt-go-database/lib/tlsutil"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"log"
 	"os"
+	"strings"
 	"time"
 )
 
-func (c *SQL) MySQL() (err error) {
-	if !c.config.Enable {
-		return fmt.Errorf("aborted, database not enable in config, double check configuration again")
+func (c *SQL) MySQL() error {
+	return c.connect(context.Background(), c.mysql)
+}
+
+func (c *SQL) mysql(config interfaces.SQLConfig) (err error) {
+	var tlsName string
+	if config.TLS.Enable {
+		tlsConfig, err := tlsutil.Config(config.TLS)
+		if err != nil {
+			return err
+		}
+
+		tlsName = "flt-" + c.tag
+		if err = mysqldriver.RegisterTLSConfig(tlsName, tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	dsn := func(config interfaces.SQLConfig) string {
+		address := parsingMysqlURL(config)
+		if len(tlsName) != 0 {
+			address = withParam(address, "tls", tlsName)
+		}
+		return address
 	}
+	address := dsn(config)
 
-	address := parsingMysqlURL(c.config)
-	c.log.Info(fmt.Sprintf("Connecting to database mysql server %s@%s:%d", c.config.Username,
-		c.config.Host, c.config.Port))
+	c.log.Info("Connecting to database mysql server %s", redact.DSN(address))
 
-	if c.db, err = gorm.Open(mysql.New(mysql.Config{
-		DSN:                       address,
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sql.OpenDB(&connector{sql: c, driver: mysqldriver.MySQLDriver{}, dsn: dsn}),
 		DefaultStringSize:         256,
 		DisableDatetimePrecision:  true,
 		DontSupportRenameIndex:    true,
@@ -37,9 +64,15 @@ func (c *SQL) MySQL() (err error) {
 				IgnoreRecordNotFoundError: true,
 			},
 		),
-	}); err != nil {
-		c.Close()
-		return c.OnError(fmt.Errorf("%s", err.Error()))
+	})
+	if err != nil {
+		release(db)
+		return redactError(err, address)
+	}
+
+	if err = db.Use(&gormTracing{tag: c.tag, system: semconv.DBSystemMySQL}); err != nil {
+		release(db)
+		return err
 	}
 
 	if !c.config.CustomPool {
@@ -48,15 +81,17 @@ func (c *SQL) MySQL() (err error) {
 		c.config.MaxConn = 10
 	}
 
-	dbConn, err := c.db.DB()
+	dbConn, err := db.DB()
 	if err != nil {
-		return c.OnError(fmt.Errorf("%s", err.Error()))
+		release(db)
+		return err
 	}
 
 	dbConn.SetMaxIdleConns(c.config.MaxIdle)
 	dbConn.SetConnMaxLifetime(time.Minute * time.Duration(c.config.LifeTime))
 	dbConn.SetMaxOpenConns(c.config.MaxConn)
 
+	c.setDB(db)
 	return nil
 }
 
@@ -75,3 +110,10 @@ func parsingMysqlURL(config interfaces.SQLConfig) (connect string) {
 	}
 	return connect
 }
+
+func withParam(dsn, key, value string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + key + "=" + value
+	}
+	return dsn + "?" + key + "=" + value
+}
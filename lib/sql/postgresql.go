@@ -1,25 +1,34 @@
 package sql
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/redact"
+	"github.com/jackc/pgx/v4/stdlib"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"log"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
-func (c *SQL) PostgresSQL() (err error) {
-	if !c.config.Enable {
-		return fmt.Errorf("aborted, database not enable in config, double check configuration again")
-	}
+func (c *SQL) PostgresSQL() error {
+	return c.connect(context.Background(), c.postgresSQL)
+}
 
-	address := c.parsingPostgresSQL()
-	c.log.Debug("Connecting to database postgresSQL server %s@%s:%d", c.config.Username,
-		c.config.Host, c.config.Port)
+func (c *SQL) postgresSQL(config interfaces.SQLConfig) (err error) {
+	address := parsingPostgresSQL(config)
+	c.log.Debug("Connecting to database postgresSQL server %s", redact.DSN(address))
 
-	if c.db, err = gorm.Open(postgres.Open(address), &gorm.Config{
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: sql.OpenDB(&connector{sql: c, driver: stdlib.GetDefaultDriver(), dsn: parsingPostgresSQL}),
+	}), &gorm.Config{
 		SkipDefaultTransaction: true,
 		PrepareStmt:            true,
 		Logger: logger.New(
@@ -30,16 +39,22 @@ func (c *SQL) PostgresSQL() (err error) {
 				IgnoreRecordNotFoundError: true,
 			},
 		),
-	}); err != nil {
-		c.Close()
-		return c.OnError(fmt.Errorf("%s", err.Error()))
+	})
+	if err != nil {
+		release(db)
+		return redactError(err, address)
 	}
 
+	if err = db.Use(&gormTracing{tag: c.tag, system: semconv.DBSystemPostgreSQL}); err != nil {
+		release(db)
+		return err
+	}
+
+	c.setDB(db)
 	return nil
 }
 
-func (c *SQL) parsingPostgresSQL() (connect string) {
-	config := c.config
+func parsingPostgresSQL(config interfaces.SQLConfig) (connect string) {
 	connect = config.Connection
 	if len(connect) == 0 {
 		connect = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s connect_timeout=%d sslmode=%s",
@@ -49,5 +64,41 @@ func (c *SQL) parsingPostgresSQL() (connect string) {
 			connect += " " + config.Options
 		}
 	}
+
+	if config.TLS.Enable {
+		connect = withPostgresTLS(connect, config.TLS)
+	}
+	return connect
+}
+
+// withPostgresTLS adds the sslmode and certificates parameters to the key/value or
+// URL connection string. ServerName and MinVersion are not supported by the driver
+// parameters.
+func withPostgresTLS(connect string, cfg interfaces.TLSConfig) string {
+	params := [][2]string{{"sslmode", "verify-full"}}
+	if cfg.InsecureSkipVerify {
+		params[0][1] = "require"
+	}
+	if len(cfg.CAFile) != 0 {
+		params = append(params, [2]string{"sslrootcert", cfg.CAFile})
+	}
+	if len(cfg.CertFile) != 0 && len(cfg.KeyFile) != 0 {
+		params = append(params, [2]string{"sslcert", cfg.CertFile}, [2]string{"sslkey", cfg.KeyFile})
+	}
+
+	if strings.HasPrefix(connect, "postgres://") || strings.HasPrefix(connect, "postgresql://") {
+		if u, err := url.Parse(connect); err == nil {
+			query := u.Query()
+			for _, param := range params {
+				query.Set(param[0], param[1])
+			}
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+
+	for _, param := range params {
+		connect += fmt.Sprintf(" %s='%s'", param[0], strings.ReplaceAll(param[1], "'", `\'`))
+	}
 	return connect
 }
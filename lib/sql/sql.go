@@ -1,41 +1,111 @@
 package sql
 
 import (
+	"context"
 	"fmt"
-	"time"
+	"strings"
+	"sync"
 
+	dberrors "github.com/fajarardiyanto/flt-go-database/errors"
 	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/lib/backoff"
+	"github.com/fajarardiyanto/flt-go-database/lib/secret"
 	logger "github.com/fajarardiyanto/flt-go-logger/interfaces"
 	"gorm.io/gorm"
 )
 
 type SQL struct {
-	reconnectAt int
-	db          *gorm.DB
-	log         logger.Logger
-	lastTimeout time.Duration
-	config      interfaces.SQLConfig
+	tag    string
+	db     *gorm.DB
+	log    logger.Logger
+	config interfaces.SQLConfig
+	conn   *backoff.Connection
+	open   func(config interfaces.SQLConfig) error
+	sync.RWMutex
 }
 
 func NewSQL(log logger.Logger, config interfaces.SQLConfig) interfaces.SQL {
-	return &SQL{
+	return NewSQLWithTag("default", log, config)
+}
+
+func NewSQLWithTag(tag string, log logger.Logger, config interfaces.SQLConfig) interfaces.SQL {
+	c := &SQL{
+		tag:    strings.ToLower(tag),
 		config: config,
 		log:    log,
 	}
+	c.conn = backoff.NewConnection("sql", c.tag, log, config.Backoff, config.StartInterval, config.MaxError, config.AutoReconnect)
+	c.conn.Connect, c.conn.Ping = c.dial, c.Ping
+	return c
 }
 
 func (c *SQL) Orm() *gorm.DB {
+	c.RLock()
+	defer c.RUnlock()
 	return c.db
 }
 
 func (c *SQL) LoadSQL() error {
+	return c.load(context.Background())
+}
+
+func (c *SQL) load(ctx context.Context) error {
 	switch c.config.Driver {
 	case "mysql":
-		return c.MySQL()
+		return c.connect(ctx, c.mysql)
 	case "postgresql":
-		return c.PostgresSQL()
+		return c.connect(ctx, c.postgresSQL)
 
 	}
 
 	return fmt.Errorf("driver '%s' not supported", c.config.Driver)
 }
+
+// connect opens the pools of the client with open, then watches it with
+// AutoReconnect.
+func (c *SQL) connect(ctx context.Context, open func(config interfaces.SQLConfig) error) error {
+	if !c.config.Enable {
+		return dberrors.New("sql", c.tag, "connect", dberrors.ErrDisabled)
+	}
+
+	c.Lock()
+	c.open = open
+	c.Unlock()
+
+	return c.conn.Start(ctx)
+}
+
+// dial opens a new pool with the resolved config, it replaces the current one.
+func (c *SQL) dial(ctx context.Context) error {
+	config, err := c.credentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.RLock()
+	open := c.open
+	c.RUnlock()
+	return open(config)
+}
+
+// credentials returns the config with its secrets resolved, on every attempt and for
+// every new connection of the pool so a rotated secret is picked up.
+func (c *SQL) credentials(ctx context.Context) (interfaces.SQLConfig, error) {
+	config := c.config
+	err := secret.ResolveAll(ctx, &config.Username, &config.Password, &config.Connection)
+	return config, err
+}
+
+func (c *SQL) Ping(ctx context.Context) error {
+	orm := c.Orm()
+	if orm == nil {
+		return dberrors.New("sql", c.tag, "ping", dberrors.ErrNotConnected)
+	}
+
+	db, err := orm.DB()
+	if err != nil {
+		return err
+	}
+
+	return db.PingContext(ctx)
+}
@@ -0,0 +1,34 @@
+//go:build integration
+// +build integration
+
+package sql_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fajarardiyanto/flt-go-database/interfaces"
+	"github.com/fajarardiyanto/flt-go-database/interfaces/interfacestest"
+	"github.com/fajarardiyanto/flt-go-database/lib/sql"
+	log "github.com/fajarardiyanto/flt-go-logger/lib"
+)
+
+// TestMySQL runs the suite against the server of INTEGRATION_MYSQL_DSN, e.g.
+// root:root@tcp(127.0.0.1:3306)/mydb?parseTime=true.
+func TestMySQL(t *testing.T) {
+	dsn := os.Getenv("INTEGRATION_MYSQL_DSN")
+	if len(dsn) == 0 {
+		t.Skip("INTEGRATION_MYSQL_DSN not set")
+	}
+
+	logger := log.NewLib()
+	logger.Init("sql integration")
+
+	interfacestest.RunSQLSuite(t, func(t *testing.T) interfaces.SQL {
+		client := sql.NewSQLWithTag(t.Name(), logger, interfaces.SQLConfig{Enable: true, Driver: "mysql", Connection: dsn})
+		if err := client.LoadSQL(); err != nil {
+			t.Fatalf("LoadSQL: %v", err)
+		}
+		return client
+	})
+}
@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"errors"
+
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// gormTracing creates a client span around every gorm operation.
+type gormTracing struct {
+	tag    string
+	system attribute.KeyValue
+}
+
+func (p *gormTracing) Name() string {
+	return "tracing"
+}
+
+func (p *gormTracing) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("tracing:before_create", p.before("create")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("tracing:after_create", p.after); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tracing:before_query", p.before("query")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("tracing:after_query", p.after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tracing:before_update", p.before("update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("tracing:after_update", p.after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("tracing:after_delete", p.after); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tracing:before_row", p.before("row")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("tracing:after_row", p.after); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("tracing:after_raw", p.after)
+}
+
+func (p *gormTracing) before(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+
+		ctx, span := tracing.Start(db.Statement.Context, "gorm."+op, trace.SpanKindClient,
+			p.system,
+			semconv.DBOperationKey.String(op),
+			tracing.TagKey.String(p.tag))
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *gormTracing) after(db *gorm.DB) {
+	val, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+
+	span, ok := val.(trace.Span)
+	if !ok {
+		return
+	}
+
+	if db.Statement != nil {
+		span.SetAttributes(semconv.DBStatementKey.String(db.Statement.SQL.String()))
+		if len(db.Statement.Table) != 0 {
+			span.SetAttributes(semconv.DBSQLTableKey.String(db.Statement.Table))
+		}
+	}
+
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
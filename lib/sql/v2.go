@@ -0,0 +1,20 @@
+package sql
+
+import (
+	"context"
+
+	databasev2 "github.com/fajarardiyanto/flt-go-database/interfaces/v2"
+)
+
+type clientV2 struct {
+	*SQL
+}
+
+// V2 returns the client with the context-first interface.
+func (c *SQL) V2() databasev2.SQL {
+	return clientV2{c}
+}
+
+func (v clientV2) Connect(ctx context.Context) error {
+	return v.load(ctx)
+}
@@ -0,0 +1,66 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	database "github.com/fajarardiyanto/flt-go-database/interfaces"
+)
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Config builds the tls.Config of the provider, it returns nil when TLS is not
+// enabled. An empty CAFile trusts the system pool and an empty MinVersion is 1.2.
+func Config(cfg database.TLSConfig) (*tls.Config, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, // #nosec G402 -- opt-in from the configuration
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if len(cfg.MinVersion) != 0 {
+		version, ok := versions[cfg.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("tls: invalid min version %q, expected 1.0, 1.1, 1.2 or 1.3", cfg.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if len(cfg.CAFile) != 0 {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: failed to read ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificate found in ca file %s", cfg.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if len(cfg.CertFile) != 0 || len(cfg.KeyFile) != 0 {
+		if len(cfg.CertFile) == 0 || len(cfg.KeyFile) == 0 {
+			return nil, fmt.Errorf("tls: both cert file and key file are required for a client certificate")
+		}
+
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
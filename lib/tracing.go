@@ -0,0 +1,13 @@
+package lib
+
+import (
+	"github.com/fajarardiyanto/flt-go-database/lib/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EnableTracing traces the brokers and the data stores of every client of the process
+// with the provider, the W3C trace context is propagated through the headers of the
+// broker messages.
+func EnableTracing(tp trace.TracerProvider) {
+	tracing.SetTracerProvider(tp)
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/fajarardiyanto/flt-go-database"
+
+// TagKey is the attribute holding the tag of the client which created the span.
+const TagKey = attribute.Key("client.tag")
+
+var (
+	provider   trace.TracerProvider          = trace.NewNoopTracerProvider()
+	propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{})
+	mutex sync.RWMutex
+)
+
+// SetTracerProvider sets the provider used by every client of the library, nothing
+// is traced until it is called.
+func SetTracerProvider(tp trace.TracerProvider) {
+	if tp == nil {
+		tp = trace.NewNoopTracerProvider()
+	}
+
+	mutex.Lock()
+	provider = tp
+	mutex.Unlock()
+}
+
+func Tracer() trace.Tracer {
+	mutex.RLock()
+	tp := provider
+	mutex.RUnlock()
+	return tp.Tracer(instrumentationName)
+}
+
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return Tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// HeaderCarrier adapts the headers of a broker message to the propagator, the values
+// other than strings and bytes are read formatted with fmt.Sprint.
+type HeaderCarrier map[string]interface{}
+
+func (c HeaderCarrier) Get(key string) string {
+	switch val := c[key].(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func (c HeaderCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Inject writes the W3C traceparent of the context into the headers.
+func Inject(ctx context.Context, headers map[string]interface{}) {
+	if ctx == nil {
+		return
+	}
+	propagator.Inject(ctx, HeaderCarrier(headers))
+}
+
+// Extract returns the context carrying the remote span found in the headers.
+func Extract(ctx context.Context, headers map[string]interface{}) context.Context {
+	return propagator.Extract(ctx, HeaderCarrier(headers))
+}